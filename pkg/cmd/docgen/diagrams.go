@@ -395,7 +395,7 @@ var specs = []stmtSpec{
 	{
 		name:    "alter_type",
 		stmt:    "alter_type_stmt",
-		inline:  []string{"opt_add_val_placement"},
+		inline:  []string{"alter_type_cmds", "alter_type_cmd", "opt_add_val_placement", "rename_value_list"},
 		replace: map[string]string{"'SCONST'": "value"},
 		unlink:  []string{"value"},
 	},
@@ -113,7 +113,7 @@ func (s *Server) refreshSettings() {
 			select {
 			case <-gossipUpdateC:
 				cfg := s.gossip.GetSystemConfig()
-				u := s.st.MakeUpdater()
+				u := s.st.MakeRefreshUpdater()
 				ok := true
 				for _, kv := range cfg.Values {
 					if err := processKV(ctx, kv, u); err != nil {
@@ -126,6 +126,8 @@ func (s *Server) refreshSettings() {
 				}
 				if ok {
 					u.ResetRemaining()
+					// The settings now reflect the settings table.
+					s.st.SV.MarkReady()
 				}
 			case <-s.stopper.ShouldStop():
 				return
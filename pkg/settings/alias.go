@@ -0,0 +1,71 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+)
+
+// alias is an old key of a setting; see RegisterAliasWithTransform.
+type alias struct {
+	newKey    string
+	transform func(oldEncoded string) (newEncoded string, err error)
+}
+
+// RegisterAliasWithTransform makes the Updaters of the default registry route
+// changes of the setting with key oldKey, which is typically retired, to the
+// setting with key newKey, which must already be registered. The encoded
+// values given for oldKey are converted by transform, e.g. from seconds to
+// milliseconds when the unit of a setting changed; errors returned by
+// transform are returned by Updater.Set.
+func RegisterAliasWithTransform(
+	oldKey, newKey string, transform func(oldEncoded string) (newEncoded string, err error),
+) {
+	defaultRegistry.RegisterAliasWithTransform(oldKey, newKey, transform)
+}
+
+// RegisterAliasWithTransform implements the Registry interface.
+func (r *registry) RegisterAliasWithTransform(
+	oldKey, newKey string, transform func(oldEncoded string) (newEncoded string, err error),
+) {
+	if _, ok := r.settings[oldKey]; ok {
+		panic(fmt.Sprintf("alias shadows a setting: %s", oldKey))
+	}
+	if _, ok := r.aliases[oldKey]; ok {
+		panic(fmt.Sprintf("alias already defined: %s", oldKey))
+	}
+	if _, ok := r.settings[newKey]; !ok {
+		panic(fmt.Sprintf("alias %s of unknown setting: %s", oldKey, newKey))
+	}
+	if r.aliases == nil {
+		r.aliases = make(map[string]alias)
+	}
+	r.aliases[oldKey] = alias{newKey: newKey, transform: transform}
+}
+
+// resolveAlias returns the key, encoded value and type to use for a change of
+// the setting with the given key, translating the change if key is an alias.
+func (r *registry) resolveAlias(key, rawValue, vt string) (string, string, string, error) {
+	a, ok := r.aliases[key]
+	if !ok {
+		return key, rawValue, vt, nil
+	}
+	newValue, err := a.transform(rawValue)
+	if err != nil {
+		return "", "", "", errors.Wrapf(err, "setting '%s' (alias of '%s')", key, a.newKey)
+	}
+	// The type given is that of the old setting, which may differ from that of
+	// the new one; it is up to transform to produce a valid encoding of the
+	// latter.
+	return a.newKey, newValue, r.settings[a.newKey].Typ(), nil
+}
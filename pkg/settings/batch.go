@@ -0,0 +1,219 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Batch accumulates typed changes to several settings so that they can be
+// applied together, e.g. from a migration, with calls chained as in
+// NewBatch().SetInt(a, 3).SetBool(b, true).WithReason("migration").Apply(sv).
+// Either all of the changes are applied or, if any of them fails validation or
+// targets a frozen setting, none are.
+type Batch struct {
+	reason string
+	sets   []batchSet
+}
+
+type batchSet struct {
+	setting  extendedSetting
+	rawValue string
+	validate func(sv *Values) error
+}
+
+// NewBatch returns an empty Batch.
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// WithReason sets the reason recorded for all the changes in the batch. It is
+// required if any of the settings in the batch was marked with
+// SetRequireReason.
+func (b *Batch) WithReason(reason string) *Batch {
+	b.reason = reason
+	return b
+}
+
+// SetInt adds a change of an int setting to the batch.
+func (b *Batch) SetInt(s *IntSetting, v int64) *Batch {
+	return b.add(s, EncodeInt(v), func(*Values) error { return s.Validate(v) })
+}
+
+// SetBool adds a change of a bool setting to the batch.
+func (b *Batch) SetBool(s *BoolSetting, v bool) *Batch {
+	return b.add(s, EncodeBool(v), nil /* validate */)
+}
+
+// SetFloat adds a change of a float setting to the batch.
+func (b *Batch) SetFloat(s *FloatSetting, v float64) *Batch {
+	return b.add(s, EncodeFloat(v), func(*Values) error { return s.Validate(v) })
+}
+
+// SetDuration adds a change of a duration setting to the batch.
+func (b *Batch) SetDuration(s *DurationSetting, v time.Duration) *Batch {
+	return b.add(s, EncodeDuration(v), func(*Values) error { return s.Validate(v) })
+}
+
+// SetString adds a change of a string setting to the batch.
+func (b *Batch) SetString(s *StringSetting, v string) *Batch {
+	return b.add(s, v, func(sv *Values) error { return s.Validate(sv, v) })
+}
+
+func (b *Batch) add(s extendedSetting, rawValue string, validate func(*Values) error) *Batch {
+	b.sets = append(b.sets, batchSet{setting: s, rawValue: rawValue, validate: validate})
+	return b
+}
+
+// Apply validates all the changes in the batch and, if they are all valid,
+// applies them to sv through a single Updater. Otherwise, sv is left untouched
+// and the validation errors of all the invalid changes are returned.
+func (b *Batch) Apply(sv *Values) error {
+	return sv.commit(func() error { return b.apply(sv) })
+}
+
+func (b *Batch) apply(sv *Values) error {
+	u := sv.getRegistry().MakeUpdater(sv).(updater)
+	u.provenance = ProvenanceBatch
+	keys := make([]string, len(b.sets))
+	var err error
+	for i, set := range b.sets {
+		key, ok := sv.getRegistry().keyOf(set.setting)
+		if !ok {
+			err = errors.CombineErrors(err, errors.AssertionFailedf("setting is not registered"))
+			continue
+		}
+		keys[i] = key
+		if cErr := u.checkCanSet(key, set.setting); cErr != nil {
+			err = errors.CombineErrors(err, cErr)
+			continue
+		}
+		if set.setting.RequiresReason() && b.reason == "" {
+			err = errors.CombineErrors(err, errors.Errorf("setting '%s' requires a reason", key))
+			continue
+		}
+		if set.validate != nil {
+			if vErr := set.validate(sv); vErr != nil {
+				err = errors.CombineErrors(err, errors.Wrapf(vErr, "setting '%s'", key))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, set := range b.sets {
+		if err := u.setWithReason(keys[i], set.rawValue, set.setting.Typ(), b.reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyOf returns the key under which s was registered.
+func (r *registry) keyOf(s extendedSetting) (string, bool) {
+	for k, v := range r.settings {
+		if v == s {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// EncodedValue is the encoded value of a setting along with the setting's
+// type, as taken by Updater.Set.
+type EncodedValue = struct{ Raw, Type string }
+
+// SetMulti sets several settings of sv to the given encoded values through a
+// single Updater, using the given reason for all of them. The values are all
+// validated first; if any of them is invalid or any of the settings is frozen,
+// none of the settings is changed and the returned error names all the
+// settings that could not be set.
+//
+// The values are validated against a copy of all the settings in sv, and then
+// applied, while holding the lock that the changes made through Updaters and
+// FreezeKeys take, so applying them doesn't fail once they were validated.
+// Should it fail nonetheless, e.g. because the validation of a setting depends
+// on state other than the settings, the changes applied before the failure
+// are kept.
+func SetMulti(sv *Values, values map[string]EncodedValue, reason string) error {
+	u := sv.getRegistry().MakeUpdater(sv).(updater)
+	u.provenance = ProvenanceSetMulti
+	return sv.commit(func() error { return u.setMulti(values, reason) })
+}
+
+// setMulti implements SetMulti. The caller must hold writeMu.
+func (u updater) setMulti(values map[string]EncodedValue, reason string) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Validate the values by applying them to a scratch copy of the settings.
+	// The scratch copy has no frozen settings, so those are checked against
+	// u.sv.
+	scratchUpdater := u
+	scratchUpdater.sv = u.sv.scratchCopy()
+	scratchUpdater.m = make(map[string]struct{})
+	var err error
+	var failed []string
+	for _, k := range keys {
+		v := values[k]
+		if key, _, _, aErr := u.r.resolveAlias(k, v.Raw, v.Type); aErr == nil {
+			if s, ok := u.r.settings[key]; ok {
+				if cErr := u.checkCanSet(key, s); cErr != nil {
+					err = errors.CombineErrors(err, cErr)
+					failed = append(failed, k)
+					continue
+				}
+			}
+		}
+		if setErr := scratchUpdater.setWithReason(k, v.Raw, v.Type, reason); setErr != nil {
+			err = errors.CombineErrors(err, setErr)
+			failed = append(failed, k)
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to set %s", strings.Join(failed, ", "))
+	}
+
+	for _, k := range keys {
+		v := values[k]
+		if err := u.setWithReason(k, v.Raw, v.Type, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateEncoding checks that encoded is a valid encoded value of the setting
+// with the given key in sv, e.g. before persisting it or when loading it from
+// storage, applying the validation of the setting but without changing its
+// value. The type of the value is that of the setting.
+func ValidateEncoding(sv *Values, key, encoded string) error {
+	r := sv.getRegistry()
+	s, ok := r.settings[key]
+	if !ok {
+		return errors.Errorf("unknown setting '%s'", key)
+	}
+	if sm, ok := s.(*StateMachineSetting); ok {
+		return sm.impl.ValidateGossipUpdate(context.TODO(), sv, []byte(encoded))
+	}
+	// As in SetMulti, the value is validated by applying it to a scratch copy
+	// of the settings.
+	return r.MakeUpdater(sv.scratchCopy()).(updater).set(key, encoded, s.Typ(), "" /* reason */)
+}
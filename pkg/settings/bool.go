@@ -25,6 +25,35 @@ func (b *BoolSetting) Get(sv *Values) bool {
 	return sv.getInt64(b.slotIdx) != 0
 }
 
+// FastValuePtr returns a pointer to the storage of the value of the setting in
+// sv, for the hottest feature gates to read it without any call overhead. The
+// pointer must be read with atomic.LoadInt64, which returns a non-zero value
+// if the setting is true. It is obtained once at setup and stays valid, and
+// reflects all the changes of the setting, for as long as sv is in use.
+//
+// Unlike Get, reads through the pointer are not recorded by the read profile.
+func (b *BoolSetting) FastValuePtr(sv *Values) *int64 {
+	return &sv.container.intVals[b.slotIdx-1]
+}
+
+// Watch returns a channel on which the value of the setting in sv is delivered
+// after each change, along with a function that stops the deliveries and
+// closes the channel. If the consumer falls behind, only the latest value is
+// kept.
+func (b *BoolSetting) Watch(sv *Values) (<-chan bool, func()) {
+	ch := make(chan bool, 1)
+	return ch, sv.watch(b.slotIdx, &watcher{
+		send: func() {
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- b.Get(sv)
+		},
+		close: func() { close(ch) },
+	})
+}
+
 func (b *BoolSetting) String(sv *Values) string {
 	return EncodeBool(b.Get(sv))
 }
@@ -78,8 +107,13 @@ func (b *BoolSetting) setToDefault(sv *Values) {
 
 // RegisterBoolSetting defines a new setting with type bool.
 func RegisterBoolSetting(key, desc string, defaultValue bool) *BoolSetting {
+	return defaultRegistry.RegisterBoolSetting(key, desc, defaultValue)
+}
+
+// RegisterBoolSetting implements the Registry interface.
+func (r *registry) RegisterBoolSetting(key, desc string, defaultValue bool) *BoolSetting {
 	setting := &BoolSetting{defaultValue: defaultValue}
-	register(key, desc, setting)
+	r.register(key, desc, setting)
 	return setting
 }
 
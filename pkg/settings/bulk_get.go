@@ -0,0 +1,92 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+// BulkGet returns the values in sv of the settings with the given keys, as
+// returned by the Get method of each setting: an int64 for int, byte size and
+// enum settings, a bool, a float64, a time.Duration or a string. Unknown keys,
+// and state machine settings which have not been set yet, are skipped. Values
+// of non-reportable settings are included, so callers rendering user-facing
+// reports must filter them out.
+//
+// Reading settings does not take any locks, so BulkGet is mostly a
+// convenience for callers such as the admin UI that render many settings at
+// once: it saves them a Lookup and a type switch per setting.
+func BulkGet(sv *Values, keys []string) map[string]interface{} {
+	r := sv.getRegistry()
+	res := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		s, ok := r.settings[k]
+		if !ok {
+			continue
+		}
+		if v, ok := typedValue(sv, s); ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// typedValue returns the value of s in sv as returned by its Get method.
+func typedValue(sv *Values, s extendedSetting) (interface{}, bool) {
+	switch setting := s.(type) {
+	case *IntSetting:
+		return setting.Get(sv), true
+	case *ByteSizeSetting:
+		return setting.Get(sv), true
+	case *EnumSetting:
+		return setting.Get(sv), true
+	case *BoolSetting:
+		return setting.Get(sv), true
+	case *FloatSetting:
+		return setting.Get(sv), true
+	case *DurationSetting:
+		return setting.Get(sv), true
+	case *DurationSettingWithExplicitUnit:
+		return setting.Get(sv), true
+	case *StringSetting:
+		return setting.Get(sv), true
+	case *StateMachineSetting:
+		// Unlike the other settings, state machine settings have no value until
+		// they are first set, in which case Get would panic.
+		if setting.GetInternal(sv) == nil {
+			return nil, false
+		}
+		return setting.Get(sv), true
+	}
+	return nil, false
+}
+
+// CollectNumericValues returns the values in sv of all the int, byte size and
+// duration settings, the latter in nanoseconds, e.g. so that the distribution
+// of the values of a setting across a fleet of clusters can be computed from
+// their telemetry. Other settings, including enum settings, are omitted, as are
+// retired settings. As with BulkGet, the values of non-reportable settings are
+// included.
+func CollectNumericValues(sv *Values) map[string]int64 {
+	res := make(map[string]int64)
+	for k, s := range sv.getRegistry().settings {
+		if s.isRetired() {
+			continue
+		}
+		switch setting := s.(type) {
+		case *IntSetting:
+			res[k] = setting.Get(sv)
+		case *ByteSizeSetting:
+			res[k] = setting.Get(sv)
+		case *DurationSetting:
+			res[k] = int64(setting.Get(sv))
+		case *DurationSettingWithExplicitUnit:
+			res[k] = int64(setting.Get(sv))
+		}
+	}
+	return res
+}
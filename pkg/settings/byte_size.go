@@ -11,6 +11,8 @@
 package settings
 
 import (
+	"strconv"
+
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -20,6 +22,11 @@ import (
 // of type "bytesize" is updated.
 type ByteSizeSetting struct {
 	IntSetting
+	// unit, if set, is the unit the values of the setting are always displayed
+	// in, and unitBytes the number of bytes in that unit; see
+	// RegisterByteSizeSettingWithUnit.
+	unit      string
+	unitBytes int64
 }
 
 var _ extendedSetting = &ByteSizeSetting{}
@@ -30,9 +37,37 @@ func (*ByteSizeSetting) Typ() string {
 }
 
 func (b *ByteSizeSetting) String(sv *Values) string {
+	if b.unit != "" {
+		v := float64(b.Get(sv)) / float64(b.unitBytes)
+		return strconv.FormatFloat(v, 'f', -1, 64) + " " + b.unit
+	}
 	return humanizeutil.IBytes(b.Get(sv))
 }
 
+// PreferredUnit returns the unit the values of the setting are displayed in,
+// or an empty string if they are displayed in the largest unit that fits them.
+func (b *ByteSizeSetting) PreferredUnit() string {
+	return b.unit
+}
+
+// ParseByteSize parses a human-readable byte size, such as "64 MiB" or "1.5GB",
+// into a number of bytes. Units are case-insensitive. IEC units, which contain
+// an "i" (KiB, MiB, GiB, ...), are powers of 1024, while SI units (KB, MB, GB,
+// ...) and the single-letter units (K, M, G, ...) are powers of 1000. A number
+// without a unit, or with the unit B, is a number of bytes. A leading '-'
+// denotes a negative size.
+//
+// This is the parser used for byte size settings; it is exported so that other
+// inputs of byte sizes, like command-line flags, accept the same syntax.
+func ParseByteSize(s string) (int64, error) {
+	// Plain numbers of bytes, which is how the values of the settings are
+	// encoded, are parsed exactly; the unit parser goes through a float64.
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	return humanizeutil.ParseBytes(s)
+}
+
 // RegisterByteSizeSetting defines a new setting with type bytesize.
 func RegisterByteSizeSetting(key, desc string, defaultValue int64) *ByteSizeSetting {
 	return RegisterValidatedByteSizeSetting(key, desc, defaultValue, nil)
@@ -45,6 +80,41 @@ func RegisterPublicByteSizeSetting(key, desc string, defaultValue int64) *ByteSi
 	return s
 }
 
+// RegisterByteSizeSettingWithMinimum defines a new setting with type bytesize
+// that cannot be set below the given minimum, nor to a negative value, e.g. for
+// caches that perform poorly when too small.
+func RegisterByteSizeSettingWithMinimum(
+	key, desc string, defaultValue, min int64,
+) *ByteSizeSetting {
+	return RegisterValidatedByteSizeSetting(key, desc, defaultValue, func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set %s to a negative value: %d", key, v)
+		}
+		if v < min {
+			return errors.Errorf("cannot set %s to %s, which is below the minimum of %s",
+				key, humanizeutil.IBytes(v), humanizeutil.IBytes(min))
+		}
+		return nil
+	})
+}
+
+// RegisterByteSizeSettingWithUnit defines a new setting with type bytesize
+// whose values are always displayed in the given unit, e.g. "MiB", for
+// consistency with related settings, rather than in the largest unit that fits
+// them. Values can still be set using any unit.
+func RegisterByteSizeSettingWithUnit(
+	key, desc string, defaultValue int64, unit string,
+) *ByteSizeSetting {
+	unitBytes, err := ParseByteSize("1" + unit)
+	if err != nil || unit == "" {
+		panic(errors.Errorf("invalid unit %q for setting %s", unit, key))
+	}
+	setting := RegisterValidatedByteSizeSetting(key, desc, defaultValue, nil)
+	setting.unit = unit
+	setting.unitBytes = unitBytes
+	return setting
+}
+
 // RegisterValidatedByteSizeSetting defines a new setting with type bytesize
 // with a validation function.
 func RegisterValidatedByteSizeSetting(
@@ -55,7 +125,7 @@ func RegisterValidatedByteSizeSetting(
 			panic(errors.Wrap(err, "invalid default"))
 		}
 	}
-	setting := &ByteSizeSetting{IntSetting{
+	setting := &ByteSizeSetting{IntSetting: IntSetting{
 		defaultValue: defaultValue,
 		validateFn:   validateFn,
 	}}
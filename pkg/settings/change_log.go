@@ -0,0 +1,113 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// changeLogCapacity is the maximum number of records kept in the change log of
+// a Values; once it is reached, the oldest records are dropped.
+const changeLogCapacity = 1000
+
+// ChangeRecord describes a change of the value of a setting made through an
+// Updater. It can be serialized, e.g. to ship it to an audit log.
+type ChangeRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Key       string    `json:"key"`
+	Old       string    `json:"old"`
+	New       string    `json:"new"`
+	// Reason is the reason given for the change, if any.
+	Reason     string     `json:"reason,omitempty"`
+	Provenance Provenance `json:"provenance"`
+}
+
+// Provenance describes how a change recorded in the change log was made.
+type Provenance string
+
+const (
+	// ProvenanceUpdater is the provenance of the changes made through the
+	// Updaters made by NewUpdater and the like.
+	ProvenanceUpdater Provenance = "updater"
+	// ProvenanceRefresh is the provenance of the values applied by the
+	// Updaters made by NewRefreshUpdater, e.g. read from system.settings.
+	ProvenanceRefresh Provenance = "refresh"
+	// ProvenanceBatch is the provenance of the changes made by Batch.Apply.
+	ProvenanceBatch Provenance = "batch"
+	// ProvenanceSetMulti is the provenance of the changes made by SetMulti.
+	ProvenanceSetMulti Provenance = "set-multi"
+	// ProvenanceTxn is the provenance of the changes committed by RunInTxn.
+	ProvenanceTxn Provenance = "txn"
+	// ProvenanceSync is the provenance of the changes applied by
+	// ApplyChanges.
+	ProvenanceSync Provenance = "sync"
+)
+
+// ExportChangeLog returns, in order, the changes of the values of the settings
+// in sv made through Updaters, Batches, SetMulti, transactions and
+// ApplyChanges since sv was initialized or the log was last drained. Sets
+// which don't change the value of a setting are not recorded. Non-reportable
+// settings are recorded with their values masked.
+//
+// Only the last changeLogCapacity (1000) changes are kept, so the log must be
+// drained at least that often for the records to be complete.
+func ExportChangeLog(sv *Values) []ChangeRecord {
+	sv.changeLog.Lock()
+	defer sv.changeLog.Unlock()
+	return append([]ChangeRecord(nil), sv.changeLog.records...)
+}
+
+// DrainChangeLog is like ExportChangeLog, but also empties the log, so that
+// the changes are not returned again, e.g. once they were shipped.
+func DrainChangeLog(sv *Values) []ChangeRecord {
+	sv.changeLog.Lock()
+	defer sv.changeLog.Unlock()
+	records := sv.changeLog.records
+	sv.changeLog.records = nil
+	return records
+}
+
+// changeLogValue returns the encoded value of s in sv, and false if changes to
+// s are not recorded in the change log.
+func changeLogValue(sv *Values, s extendedSetting) (string, bool) {
+	if _, ok := s.(*StateMachineSetting); ok {
+		// State machine settings, i.e. the cluster version, have their own
+		// upgrade machinery.
+		return "", false
+	}
+	return s.Encoded(sv), true
+}
+
+// logChange records a change of the setting with the given key in the change
+// log, unless its value did not change.
+func (sv *Values) logChange(key, oldVal, newVal, reason string, provenance Provenance) {
+	if oldVal == newVal {
+		return
+	}
+	if !sv.getRegistry().settings[key].isReportable() {
+		oldVal, newVal = redactedValue, redactedValue
+	}
+	sv.changeLog.Lock()
+	defer sv.changeLog.Unlock()
+	if len(sv.changeLog.records) == changeLogCapacity {
+		sv.changeLog.records = sv.changeLog.records[1:]
+	}
+	sv.changeLog.records = append(sv.changeLog.records, ChangeRecord{
+		Timestamp:  timeutil.Now(),
+		Key:        key,
+		Old:        oldVal,
+		New:        newVal,
+		Reason:     reason,
+		Provenance: provenance,
+	})
+}
@@ -51,6 +51,14 @@ type Settings struct {
 	Version clusterversion.Handle
 }
 
+func init() {
+	// Log the failures of the OnChange callbacks of settings, rather than
+	// printing them to stderr.
+	settings.SetErrorHandler(func(key string, err error) {
+		log.Errorf(context.Background(), "OnChange callback of setting '%s' failed: %+v", key, err)
+	})
+}
+
 // TelemetryOptOut is a place for controlling whether to opt out of telemetry or not.
 func TelemetryOptOut() bool {
 	return envutil.EnvOrDefaultBool("COCKROACH_SKIP_ENABLING_DIAGNOSTIC_REPORTING", false)
@@ -107,6 +115,16 @@ func (s *Settings) MakeUpdater() settings.Updater {
 	return settings.NewUpdater(&s.SV)
 }
 
+// MakeRefreshUpdater is like MakeUpdater, but the returned Updater applies
+// values that were already accepted, e.g. those read from system.settings; see
+// settings.NewRefreshUpdater.
+func (s *Settings) MakeRefreshUpdater() settings.Updater {
+	if isManual, ok := s.Manual.Load().(bool); ok && isManual {
+		return &settings.NoopUpdater{}
+	}
+	return settings.NewRefreshUpdater(&s.SV)
+}
+
 // MakeClusterSettings returns a Settings object that has its binary and
 // minimum supported versions set to this binary's build and it's minimum
 // supported versions respectively. The cluster version setting is not
@@ -0,0 +1,92 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import "github.com/cockroachdb/errors"
+
+// CoalescedSetting is a read-only view over two settings of the same type. It
+// reads the primary setting if it has been changed from its default value, and
+// the fallback setting otherwise. It is created with Coalesce.
+type CoalescedSetting struct {
+	primary  WritableSetting
+	fallback WritableSetting
+}
+
+var _ Setting = &CoalescedSetting{}
+
+// Coalesce returns a view reading primary if it has been changed from its
+// default value, and fallback otherwise. This is useful when a setting
+// supersedes an older one, which remains in effect until the new one is set.
+// Both settings must have the same type.
+func Coalesce(primary, fallback Setting) *CoalescedSetting {
+	p, ok := primary.(WritableSetting)
+	if !ok {
+		panic(errors.AssertionFailedf("cannot coalesce a %T", primary))
+	}
+	f, ok := fallback.(WritableSetting)
+	if !ok {
+		panic(errors.AssertionFailedf("cannot coalesce a %T", fallback))
+	}
+	if p.Typ() != f.Typ() {
+		panic(errors.AssertionFailedf("cannot coalesce settings of types %s and %s", p.Typ(), f.Typ()))
+	}
+	return &CoalescedSetting{primary: p, fallback: f}
+}
+
+// Effective returns whichever of the primary and fallback settings is in
+// effect in sv.
+func (c *CoalescedSetting) Effective(sv *Values) WritableSetting {
+	if c.primary.Encoded(sv) != c.primary.EncodedDefault() {
+		return c.primary
+	}
+	return c.fallback
+}
+
+// Get returns the encoded value of the setting in effect in sv.
+func (c *CoalescedSetting) Get(sv *Values) string {
+	return c.Effective(sv).Encoded(sv)
+}
+
+// Typ returns the short (1 char) string denoting the type of setting.
+func (c *CoalescedSetting) Typ() string {
+	return c.primary.Typ()
+}
+
+func (c *CoalescedSetting) String(sv *Values) string {
+	return c.Effective(sv).String(sv)
+}
+
+// Description returns the description of the primary setting.
+func (c *CoalescedSetting) Description() string {
+	return c.primary.Description()
+}
+
+// Visibility returns the visibility of the primary setting.
+func (c *CoalescedSetting) Visibility() Visibility {
+	return c.primary.Visibility()
+}
+
+// RequiresReason implements the Setting interface. A CoalescedSetting cannot be
+// changed, so it never requires a reason.
+func (c *CoalescedSetting) RequiresReason() bool {
+	return false
+}
+
+// Protected implements the Setting interface. A CoalescedSetting cannot be
+// changed, so it is never protected.
+func (c *CoalescedSetting) Protected() bool {
+	return false
+}
+
+// PreferredUnit returns the display unit of the primary setting.
+func (c *CoalescedSetting) PreferredUnit() string {
+	return c.primary.PreferredUnit()
+}
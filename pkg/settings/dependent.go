@@ -0,0 +1,163 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+)
+
+// RegisterDependent declares that the setting child in sv is derived from the
+// parents settings. Whenever the value of one of the parents changes in sv,
+// child is reset to its default, so that it never keeps a value derived from
+// the previous values of the parents, and recompute is called, e.g. to set
+// child to a value derived from the new ones. This happens once per commit
+// changing any of the parents, e.g. once for a SetMulti or a Batch changing
+// several of them, after the commit has been applied; changes of the parents
+// made concurrently with a commit, other than through Updaters, may be
+// coalesced with it. Errors returned by recompute are passed, along with the
+// key of child, to the handler installed by SetErrorHandler.
+func RegisterDependent(
+	sv *Values, child WritableSetting, parents []WritableSetting, recompute func() error,
+) {
+	d := &dependent{recompute: recompute}
+	var ok bool
+	if d.child, ok = child.(extendedSetting); !ok {
+		panic(errors.AssertionFailedf("cannot register a dependent %T", child))
+	}
+	d.key, _ = sv.getRegistry().keyOf(d.child)
+	for _, p := range parents {
+		es, ok := p.(extendedSetting)
+		if !ok {
+			panic(errors.AssertionFailedf("cannot depend on a %T", p))
+		}
+		d.parentSlots = append(d.parentSlots, es.getSlotIdx())
+	}
+	sv.dependents.Lock()
+	defer sv.dependents.Unlock()
+	sv.dependents.list = append(sv.dependents.list, d)
+	atomic.StoreInt32(&sv.dependents.count, int32(len(sv.dependents.list)))
+	for _, slotIdx := range d.slots() {
+		sv.getRegistry().addSlotCallbacks(slotIdx, 1)
+	}
+}
+
+// removeDependents removes the dependents whose child or one of whose parents
+// is the setting in the given slot; see unregister.
+func (sv *Values) removeDependents(slotIdx int) {
+	sv.dependents.Lock()
+	defer sv.dependents.Unlock()
+	list := sv.dependents.list[:0:0]
+	for _, d := range sv.dependents.list {
+		if !d.uses(slotIdx) {
+			list = append(list, d)
+			continue
+		}
+		for _, s := range d.slots() {
+			sv.getRegistry().addSlotCallbacks(s, -1)
+		}
+	}
+	sv.dependents.list = list
+	atomic.StoreInt32(&sv.dependents.count, int32(len(list)))
+}
+
+// dependent is a setting registered with RegisterDependent.
+type dependent struct {
+	key         string
+	child       extendedSetting
+	parentSlots []int
+	recompute   func() error
+}
+
+// slots returns the slots of the child and the parents of d.
+func (d *dependent) slots() []int {
+	return append([]int{d.child.getSlotIdx()}, d.parentSlots...)
+}
+
+// uses returns whether the child or one of the parents of d is the setting in
+// the given slot.
+func (d *dependent) uses(slotIdx int) bool {
+	for _, s := range d.slots() {
+		if s == slotIdx {
+			return true
+		}
+	}
+	return false
+}
+
+// beginDependents starts collecting the parents changed by a commit, so that
+// their dependents are recomputed once, when the commit ends.
+func (sv *Values) beginDependents() {
+	if atomic.LoadInt32(&sv.dependents.count) == 0 {
+		return
+	}
+	sv.dependents.Lock()
+	defer sv.dependents.Unlock()
+	sv.dependents.changed = make(map[int]struct{})
+}
+
+// endDependents stops collecting the parents changed by a commit and returns
+// them.
+func (sv *Values) endDependents() map[int]struct{} {
+	if atomic.LoadInt32(&sv.dependents.count) == 0 {
+		return nil
+	}
+	sv.dependents.Lock()
+	defer sv.dependents.Unlock()
+	changed := sv.dependents.changed
+	sv.dependents.changed = nil
+	return changed
+}
+
+// parentChanged records that the setting in the given slot changed. Its
+// dependents are recomputed right away, unless a commit is in progress.
+func (sv *Values) parentChanged(slotIdx int) {
+	if atomic.LoadInt32(&sv.dependents.count) == 0 {
+		return
+	}
+	sv.dependents.Lock()
+	if changed := sv.dependents.changed; changed != nil {
+		changed[slotIdx] = struct{}{}
+		sv.dependents.Unlock()
+		return
+	}
+	sv.dependents.Unlock()
+	sv.recomputeDependents(map[int]struct{}{slotIdx: {}})
+}
+
+// recomputeDependents recomputes, once each, the dependents of the settings
+// in the given slots.
+func (sv *Values) recomputeDependents(changed map[int]struct{}) {
+	if len(changed) == 0 {
+		return
+	}
+	var toRecompute []*dependent
+	sv.dependents.Lock()
+	for _, d := range sv.dependents.list {
+		for _, slotIdx := range d.parentSlots {
+			if _, ok := changed[slotIdx]; ok {
+				toRecompute = append(toRecompute, d)
+				break
+			}
+		}
+	}
+	sv.dependents.Unlock()
+	for _, d := range toRecompute {
+		_ = sv.commit(func() error {
+			d.child.setToDefault(sv)
+			return nil
+		})
+		if err := d.recompute(); err != nil {
+			reportError(d.key, errors.Wrap(err, "recomputing dependent setting"))
+		}
+	}
+}
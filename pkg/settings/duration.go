@@ -45,6 +45,24 @@ func (d *DurationSetting) Get(sv *Values) time.Duration {
 	return time.Duration(sv.getInt64(d.slotIdx))
 }
 
+// Watch returns a channel on which the value of the setting in sv is delivered
+// after each change, along with a function that stops the deliveries and
+// closes the channel. If the consumer falls behind, only the latest value is
+// kept.
+func (d *DurationSetting) Watch(sv *Values) (<-chan time.Duration, func()) {
+	ch := make(chan time.Duration, 1)
+	return ch, sv.watch(d.slotIdx, &watcher{
+		send: func() {
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- d.Get(sv)
+		},
+		close: func() { close(ch) },
+	})
+}
+
 func (d *DurationSetting) String(sv *Values) string {
 	return EncodeDuration(d.Get(sv))
 }
@@ -110,6 +128,15 @@ func RegisterDurationSetting(key, desc string, defaultValue time.Duration) *Dura
 	return RegisterValidatedDurationSetting(key, desc, defaultValue, nil)
 }
 
+// RegisterDurationSetting implements the Registry interface.
+func (r *registry) RegisterDurationSetting(
+	key, desc string, defaultValue time.Duration,
+) *DurationSetting {
+	setting := &DurationSetting{defaultValue: defaultValue}
+	r.register(key, desc, setting)
+	return setting
+}
+
 // RegisterPublicDurationSetting defines a new setting with type
 // duration and makes it public.
 func RegisterPublicDurationSetting(key, desc string, defaultValue time.Duration) *DurationSetting {
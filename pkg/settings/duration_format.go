@@ -0,0 +1,110 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// FormatDuration formats a duration the way the values of duration settings
+// are displayed, i.e. in Go's format, e.g. "1h30m0s". ParseDuration accepts
+// its output.
+func FormatDuration(d time.Duration) string {
+	return d.String()
+}
+
+// ParseDuration parses a duration given either in Go's format, e.g. "1h30m",
+// or in the ISO 8601 format, e.g. "PT1H30M", as accepted by duration settings.
+// ISO 8601 durations may use weeks, days (taken to be 24 hours), hours,
+// minutes and seconds, but not months or years, whose lengths vary. The units
+// must appear in that order, each at most once, and only the last may have a
+// fraction.
+func ParseDuration(s string) (time.Duration, error) {
+	if strings.HasPrefix(strings.TrimPrefix(strings.ToUpper(s), "-"), "P") {
+		return parseISO8601Duration(s)
+	}
+	return time.ParseDuration(s)
+}
+
+func parseISO8601Duration(s string) (time.Duration, error) {
+	invalid := func() error {
+		return errors.Errorf("invalid ISO 8601 duration %q", s)
+	}
+	rest := strings.ToUpper(s)
+	neg := strings.HasPrefix(rest, "-")
+	rest = strings.TrimPrefix(strings.TrimPrefix(rest, "-"), "P")
+	if rest == "" {
+		return 0, invalid()
+	}
+	var d time.Duration
+	inTime := false
+	// last is the position in the order W, D, H, M, S of the last unit
+	// parsed; each unit must follow the previous ones in that order, which
+	// also allows it at most once.
+	last := -1
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime || len(rest) == 1 {
+				return 0, invalid()
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, invalid()
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, invalid()
+		}
+		var unit time.Duration
+		var pos int
+		switch {
+		case !inTime && rest[i] == 'W':
+			unit, pos = 7*24*time.Hour, 0
+		case !inTime && rest[i] == 'D':
+			unit, pos = 24*time.Hour, 1
+		case inTime && rest[i] == 'H':
+			unit, pos = time.Hour, 2
+		case inTime && rest[i] == 'M':
+			unit, pos = time.Minute, 3
+		case inTime && rest[i] == 'S':
+			unit, pos = time.Second, 4
+		default:
+			return 0, invalid()
+		}
+		if pos <= last {
+			return 0, invalid()
+		}
+		last = pos
+		// Only the last component may have a fraction.
+		if strings.Contains(rest[:i], ".") && i+1 < len(rest) {
+			return 0, invalid()
+		}
+		v := n*float64(unit) + float64(d)
+		if v > math.MaxInt64 {
+			return 0, errors.Errorf("ISO 8601 duration %q is out of range", s)
+		}
+		d = time.Duration(v)
+		rest = rest[i+1:]
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
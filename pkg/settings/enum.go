@@ -36,12 +36,19 @@ func (e *EnumSetting) Typ() string {
 // String returns the enum's string value.
 func (e *EnumSetting) String(sv *Values) string {
 	enumID := e.Get(sv)
-	if str, ok := e.enumValues[enumID]; ok {
+	if str, ok := EnumLabel(e, enumID); ok {
 		return str
 	}
 	return fmt.Sprintf("unknown(%d)", enumID)
 }
 
+// EnumLabel returns the (lowercase) label of the value v of the enum setting
+// s, and false if v is not one of the setting's values.
+func EnumLabel(s *EnumSetting, v int64) (string, bool) {
+	str, ok := s.enumValues[v]
+	return str, ok
+}
+
 // ParseEnum returns the enum value, and a boolean that indicates if it was parseable.
 func (e *EnumSetting) ParseEnum(raw string) (int64, bool) {
 	rawLower := strings.ToLower(raw)
@@ -0,0 +1,90 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import "strings"
+
+// MaskedEnvValue is the placeholder that ExportEnv emits instead of the values
+// of non-reportable settings. ApplyDefaultsFromEnv ignores variables set to it.
+const MaskedEnvValue = redactedValue
+
+// EnvVarName returns the name of the environment variable that
+// ApplyDefaultsFromEnv reads the setting with the given key from: the key,
+// upper-cased and with every character other than letters, digits and
+// underscores, e.g. dots and dashes, replaced by an underscore, prefixed with
+// COCKROACH_. For example, kv.bulk-io.enabled maps to
+// COCKROACH_KV_BULK_IO_ENABLED. The result is a name that POSIX shells can set.
+func EnvVarName(key string) string {
+	return "COCKROACH_" + strings.Map(envVarNameRune, strings.ToUpper(key))
+}
+
+// envVarNameRune maps the characters of an upper-cased key to those of the
+// name of its environment variable.
+func envVarNameRune(r rune) rune {
+	if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+		return r
+	}
+	return '_'
+}
+
+// ExportEnv returns, sorted by key, a NAME=value line for each setting whose
+// value in sv differs from its default, using the variable names read by
+// ApplyDefaultsFromEnv. This allows the configuration of a running cluster to
+// be baked into the environment of a deployment. The values of non-reportable
+// settings are replaced with MaskedEnvValue. State machine settings are not
+// exported.
+func ExportEnv(sv *Values) []string {
+	r := sv.getRegistry()
+	var res []string
+	for _, k := range r.Keys() {
+		s := r.settings[k]
+		if _, ok := s.(*StateMachineSetting); ok {
+			// State machine settings, like the cluster version, are not
+			// configured through defaults.
+			continue
+		}
+		v := s.Encoded(sv)
+		if v == s.EncodedDefault() {
+			continue
+		}
+		if !s.isReportable() {
+			v = MaskedEnvValue
+		}
+		res = append(res, EnvVarName(k)+"="+v)
+	}
+	return res
+}
+
+// ApplyDefaultsFromEnv installs the values of the environment variables named
+// by EnvVarName as the defaults of the respective settings in sv, like
+// ApplyDefaultsFromFile does for the entries of a file. environ is in the
+// format of os.Environ. Variables that don't name a setting, or that are set
+// to MaskedEnvValue, are ignored.
+func ApplyDefaultsFromEnv(sv *Values, environ []string) error {
+	r := sv.getRegistry()
+	keysByVar := make(map[string]string, len(r.settings))
+	for _, k := range r.Keys() {
+		keysByVar[EnvVarName(k)] = k
+	}
+	entries := make(map[string]string)
+	for _, kv := range environ {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			continue
+		}
+		k, ok := keysByVar[kv[:i]]
+		if !ok || kv[i+1:] == MaskedEnvValue {
+			continue
+		}
+		entries[k] = kv[i+1:]
+	}
+	return applyDefaults(sv, "environment", entries)
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+)
+
+// errorHandler stores the func(key string, err error) installed by
+// SetErrorHandler.
+var errorHandler atomic.Value
+
+func init() {
+	SetErrorHandler(nil)
+}
+
+// SetErrorHandler installs the function called when an OnChange callback of a
+// setting fails, with the key of the setting and the error. A panic in a
+// callback is recovered and converted to an error: the new value of the
+// setting is kept, and the remaining callbacks still run. Passing nil
+// restores the default handler, which prints the error to stderr; servers
+// replace it with one that logs, see the cluster package.
+func SetErrorHandler(fn func(key string, err error)) {
+	if fn == nil {
+		fn = func(key string, err error) {
+			fmt.Fprintf(os.Stderr, "OnChange callback of setting '%s' failed: %+v\n", key, err)
+		}
+	}
+	errorHandler.Store(fn)
+}
+
+// runOnChange runs an OnChange callback of the setting in the given slot,
+// reporting a panic to the error handler.
+func (sv *Values) runOnChange(slotIdx int, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if ok {
+				err = errors.Wrap(err, "panic in OnChange callback")
+			} else {
+				err = errors.Errorf("panic in OnChange callback: %v", r)
+			}
+			reportError(sv.getRegistry().keyForSlot(slotIdx), err)
+		}
+	}()
+	fn()
+}
+
+// reportError passes an error related to the setting with the given key to the
+// handler installed by SetErrorHandler.
+func reportError(key string, err error) {
+	handler := errorHandler.Load().(func(key string, err error))
+	handler(key, err)
+}
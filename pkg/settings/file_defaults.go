@@ -0,0 +1,184 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ApplyDefaultsFromFile reads a YAML (or JSON) file mapping setting keys to
+// encoded values (e.g. "server.time_until_store_dead: 10m") and installs each
+// value as the default of the respective setting in sv.
+//
+// The file defaults take precedence over the compiled-in defaults, but not
+// over values set through an Updater: a setting that is reset (or not
+// included in an Updater) reverts to its file default. Every entry is
+// validated before any is installed, so a malformed file leaves sv untouched.
+//
+// This is meant to be called at startup, before any values are read from the
+// cluster.
+func ApplyDefaultsFromFile(sv *Values, path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "reading settings defaults file")
+	}
+	var entries map[string]string
+	if err := yaml.UnmarshalStrict(b, &entries); err != nil {
+		return errors.Wrapf(err, "parsing settings defaults file %s", path)
+	}
+	return applyDefaults(sv, path, entries)
+}
+
+// applyDefaults installs the given encoded values, keyed by setting key, as
+// the defaults of the respective settings in sv. Every value is validated
+// before any is installed. source names where the values come from in errors.
+func applyDefaults(sv *Values, source string, entries map[string]string) error {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	defaults := make([]interface{}, len(keys))
+	for i, k := range keys {
+		s, ok := sv.getRegistry().settings[k]
+		if !ok {
+			return errors.Errorf("%s: unknown setting '%s'", source, k)
+		}
+		var err error
+		defaults[i], err = parseDefault(sv, s, entries[k])
+		if err != nil {
+			return errors.Wrapf(err, "%s: invalid default for setting '%s'", source, k)
+		}
+	}
+	for i, k := range keys {
+		s := sv.getRegistry().settings[k]
+		installDefault(sv, s, defaults[i])
+		s.setToDefault(sv)
+	}
+	return nil
+}
+
+// TestingSetDefault changes the default value of s in sv to the given encoded
+// value, until the returned function is called. Unlike Override, this does not
+// change the current value of the setting, only the value it reverts to when
+// it is reset. This lets tests pin the defaults they rely on.
+func TestingSetDefault(sv *Values, s Setting, encodedDefault string) (restore func()) {
+	es, ok := s.(extendedSetting)
+	if !ok {
+		panic(errors.AssertionFailedf("cannot change the default of a %T", s))
+	}
+	d, err := parseDefault(sv, es, encodedDefault)
+	if err != nil {
+		panic(errors.Wrap(err, "invalid default"))
+	}
+	slotIdx := es.getSlotIdx()
+	hadOverride, prevInt, prevGeneric := sv.getDefaultOverride(slotIdx)
+	installDefault(sv, es, d)
+	return func() {
+		if !hadOverride {
+			sv.clearDefaultOverride(slotIdx)
+			return
+		}
+		sv.setDefaultOverrideInt64(slotIdx, prevInt)
+		if prevGeneric != nil {
+			sv.setDefaultOverrideGeneric(slotIdx, prevGeneric)
+		}
+	}
+}
+
+// installDefault installs a default value returned by parseDefault as the
+// default of s in sv.
+func installDefault(sv *Values, s extendedSetting, d interface{}) {
+	switch v := d.(type) {
+	case int64:
+		sv.setDefaultOverrideInt64(s.getSlotIdx(), v)
+	case string:
+		sv.setDefaultOverrideGeneric(s.getSlotIdx(), v)
+	}
+}
+
+// parseDefault parses and validates the encoded default value of the given
+// setting. It returns either the int64 or the string that is to be stored as
+// the overridden default, depending on the setting type.
+func parseDefault(sv *Values, s extendedSetting, rawValue string) (interface{}, error) {
+	switch setting := s.(type) {
+	case *StringSetting:
+		if err := setting.Validate(sv, rawValue); err != nil {
+			return nil, err
+		}
+		return rawValue, nil
+	case *BoolSetting:
+		b, err := strconv.ParseBool(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case *EnumSetting:
+		i, ok := setting.ParseEnum(rawValue)
+		if !ok {
+			return nil, errors.Errorf("unrecognized value %s", rawValue)
+		}
+		return i, nil
+	case *ByteSizeSetting:
+		i, err := ParseByteSize(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		if err := setting.Validate(i); err != nil {
+			return nil, err
+		}
+		return i, nil
+	case numericSetting:
+		i, err := strconv.ParseInt(rawValue, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if err := setting.Validate(i); err != nil {
+			return nil, err
+		}
+		return i, nil
+	case *FloatSetting:
+		f, err := strconv.ParseFloat(rawValue, 64)
+		if err != nil {
+			return nil, err
+		}
+		if err := setting.Validate(f); err != nil {
+			return nil, err
+		}
+		return int64(math.Float64bits(f)), nil
+	case *DurationSetting:
+		return parseDurationDefault(setting, rawValue)
+	case *DurationSettingWithExplicitUnit:
+		return parseDurationDefault(&setting.DurationSetting, rawValue)
+	}
+	return nil, errors.Errorf("settings of type %s do not support defaults", s.Typ())
+}
+
+func parseDurationDefault(setting *DurationSetting, rawValue string) (interface{}, error) {
+	d, err := ParseDuration(rawValue)
+	if err != nil {
+		return nil, err
+	}
+	if err := setting.Validate(d); err != nil {
+		return nil, err
+	}
+	return int64(d), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ParseFlagEnum parses a comma-separated list of flag labels, e.g. "a, b ,c",
+// into the bitwise OR of the flags they denote according to labels, which maps
+// each flag to its label. Labels are matched case-insensitively and the
+// whitespace around them is ignored; a flag may be listed several times. An
+// empty list denotes no flags.
+func ParseFlagEnum(s string, labels map[int64]string) (int64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	var flags int64
+	for _, label := range strings.Split(s, ",") {
+		label = strings.ToLower(strings.TrimSpace(label))
+		found := false
+		for k, v := range labels {
+			if strings.ToLower(v) == label {
+				flags |= k
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, errors.Errorf("unknown flag %q in %q, valid flags are %s",
+				label, s, enumValuesToDesc(labels))
+		}
+	}
+	return flags, nil
+}
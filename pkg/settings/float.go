@@ -32,6 +32,24 @@ func (f *FloatSetting) Get(sv *Values) float64 {
 	return math.Float64frombits(uint64(sv.getInt64(f.slotIdx)))
 }
 
+// Watch returns a channel on which the value of the setting in sv is delivered
+// after each change, along with a function that stops the deliveries and
+// closes the channel. If the consumer falls behind, only the latest value is
+// kept.
+func (f *FloatSetting) Watch(sv *Values) (<-chan float64, func()) {
+	ch := make(chan float64, 1)
+	return ch, sv.watch(f.slotIdx, &watcher{
+		send: func() {
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- f.Get(sv)
+		},
+		close: func() { close(ch) },
+	})
+}
+
 func (f *FloatSetting) String(sv *Values) string {
 	return EncodeFloat(f.Get(sv))
 }
@@ -104,6 +122,13 @@ func RegisterFloatSetting(key, desc string, defaultValue float64) *FloatSetting
 	return RegisterValidatedFloatSetting(key, desc, defaultValue, nil)
 }
 
+// RegisterFloatSetting implements the Registry interface.
+func (r *registry) RegisterFloatSetting(key, desc string, defaultValue float64) *FloatSetting {
+	setting := &FloatSetting{defaultValue: defaultValue}
+	r.register(key, desc, setting)
+	return setting
+}
+
 // RegisterNonNegativeFloatSetting defines a new setting with type float.
 func RegisterNonNegativeFloatSetting(key, desc string, defaultValue float64) *FloatSetting {
 	return RegisterValidatedFloatSetting(key, desc, defaultValue, func(v float64) error {
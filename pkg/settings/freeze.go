@@ -0,0 +1,81 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrFrozen is the error, possibly wrapped, returned by Updater.Set when
+// changing a setting frozen by FreezeKeys.
+var ErrFrozen = errors.New("setting is frozen")
+
+// keyFreeze is a single call to FreezeKeys.
+type keyFreeze struct {
+	reason string
+}
+
+// FreezeKeys makes the Updaters of sv reject changes to the settings with the
+// given keys, e.g. to protect them during a migration, while still allowing
+// changes to all other settings. Freezes stack: a key remains frozen until all
+// the freezes including it are released by calling the returned function.
+func FreezeKeys(sv *Values, keys []string, reason string) (unfreeze func()) {
+	f := &keyFreeze{reason: reason}
+	// Wait for the changes being applied, which may have been validated
+	// before the keys were frozen.
+	sv.writeMu.Lock()
+	defer sv.writeMu.Unlock()
+	sv.frozenMu.Lock()
+	if sv.frozenMu.keys == nil {
+		sv.frozenMu.keys = make(map[string][]*keyFreeze)
+	}
+	for _, k := range keys {
+		sv.frozenMu.keys[k] = append(sv.frozenMu.keys[k], f)
+	}
+	sv.frozenMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sv.frozenMu.Lock()
+			defer sv.frozenMu.Unlock()
+			for _, k := range keys {
+				freezes := sv.frozenMu.keys[k]
+				for i := range freezes {
+					if freezes[i] == f {
+						freezes = append(freezes[:i:i], freezes[i+1:]...)
+						break
+					}
+				}
+				if len(freezes) == 0 {
+					delete(sv.frozenMu.keys, k)
+				} else {
+					sv.frozenMu.keys[k] = freezes
+				}
+			}
+		})
+	}
+}
+
+// checkNotFrozen returns an error wrapping ErrFrozen if the setting with the
+// given key is frozen. The reason of the most recent freeze is reported.
+func (sv *Values) checkNotFrozen(key string) error {
+	sv.frozenMu.Lock()
+	defer sv.frozenMu.Unlock()
+	freezes := sv.frozenMu.keys[key]
+	if len(freezes) == 0 {
+		return nil
+	}
+	return errors.Wrapf(ErrFrozen, "cannot change setting '%s' (%s)",
+		key, freezes[len(freezes)-1].reason)
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+)
+
+// RegisterGauge publishes the value of the numeric setting with the given key
+// in sv as a gauge: register is called once with the MetricName of the setting
+// and a function returning its current value, which is kept up to date through
+// the OnChange callbacks of the setting. Durations are published in
+// nanoseconds, bools as 0 or 1, and enums as the code of their value.
+func RegisterGauge(sv *Values, key string, register func(name string, get func() float64)) error {
+	s, ok := sv.getRegistry().settings[key]
+	if !ok {
+		return errors.Errorf("unknown setting '%s'", key)
+	}
+	if _, ok := gaugeValue(sv, s); !ok {
+		return errors.Errorf("setting '%s' of type %s cannot be published as a gauge", key, s.Typ())
+	}
+	var bits uint64
+	update := func() {
+		v, _ := gaugeValue(sv, s)
+		atomic.StoreUint64(&bits, math.Float64bits(v))
+	}
+	update()
+	s.SetOnChange(sv, update)
+	register(MetricName(key), func() float64 {
+		return math.Float64frombits(atomic.LoadUint64(&bits))
+	})
+	return nil
+}
+
+// gaugeValue returns the value of s in sv as published by RegisterGauge, or
+// false if s is not numeric.
+func gaugeValue(sv *Values, s extendedSetting) (float64, bool) {
+	switch setting := s.(type) {
+	case *IntSetting:
+		return float64(setting.Get(sv)), true
+	case *ByteSizeSetting:
+		return float64(setting.Get(sv)), true
+	case *EnumSetting:
+		return float64(setting.Get(sv)), true
+	case *FloatSetting:
+		return setting.Get(sv), true
+	case *DurationSetting:
+		return float64(setting.Get(sv)), true
+	case *DurationSettingWithExplicitUnit:
+		return float64(setting.Get(sv)), true
+	case *BoolSetting:
+		if setting.Get(sv) {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
@@ -10,7 +10,11 @@
 
 package settings
 
-import "github.com/cockroachdb/errors"
+import (
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
 
 // IntSetting is the interface of a setting variable that will be
 // updated automatically when the corresponding cluster-wide setting
@@ -19,6 +23,17 @@ type IntSetting struct {
 	common
 	defaultValue int64
 	validateFn   func(int64) error
+	// dynamicDefault, if set, computes the default value instead of
+	// defaultValue; see RegisterIntSettingWithDynamicDefault.
+	dynamicDefault *dynamicIntDefault
+}
+
+// dynamicIntDefault is the default value of an int setting computed by a
+// function, which is called once, the first time the default is needed.
+type dynamicIntDefault struct {
+	once sync.Once
+	fn   func() int64
+	v    int64
 }
 
 var _ extendedSetting = &IntSetting{}
@@ -28,6 +43,24 @@ func (i *IntSetting) Get(sv *Values) int64 {
 	return sv.container.getInt64(i.slotIdx)
 }
 
+// Watch returns a channel on which the value of the setting in sv is delivered
+// after each change, along with a function that stops the deliveries and
+// closes the channel. If the consumer falls behind, only the latest value is
+// kept.
+func (i *IntSetting) Watch(sv *Values) (<-chan int64, func()) {
+	ch := make(chan int64, 1)
+	return ch, sv.watch(i.slotIdx, &watcher{
+		send: func() {
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- i.Get(sv)
+		},
+		close: func() { close(ch) },
+	})
+}
+
 func (i *IntSetting) String(sv *Values) string {
 	return EncodeInt(i.Get(sv))
 }
@@ -39,7 +72,7 @@ func (i *IntSetting) Encoded(sv *Values) string {
 
 // EncodedDefault returns the encoded value of the default value of the setting.
 func (i *IntSetting) EncodedDefault() string {
-	return EncodeInt(i.defaultValue)
+	return EncodeInt(i.Default())
 }
 
 // Typ returns the short (1 char) string denoting the type of setting.
@@ -83,13 +116,17 @@ func (i *IntSetting) setToDefault(sv *Values) {
 		_ = i.set(sv, val)
 		return
 	}
-	if err := i.set(sv, i.defaultValue); err != nil {
+	if err := i.set(sv, i.Default()); err != nil {
 		panic(err)
 	}
 }
 
 // Default returns the default value.
 func (i *IntSetting) Default() int64 {
+	if d := i.dynamicDefault; d != nil {
+		d.once.Do(func() { d.v = d.fn() })
+		return d.v
+	}
 	return i.defaultValue
 }
 
@@ -98,6 +135,34 @@ func RegisterIntSetting(key, desc string, defaultValue int64) *IntSetting {
 	return RegisterValidatedIntSetting(key, desc, defaultValue, nil)
 }
 
+// RegisterIntSetting implements the Registry interface.
+func (r *registry) RegisterIntSetting(key, desc string, defaultValue int64) *IntSetting {
+	setting := &IntSetting{defaultValue: defaultValue}
+	r.register(key, desc, setting)
+	return setting
+}
+
+// RegisterIntSettingWithDynamicDefault defines a new setting with type int
+// whose default value is computed by defaultFn rather than being a constant,
+// for defaults that depend on the machine, e.g. on GOMAXPROCS. defaultFn is
+// called once, the first time the default is needed, which is at the latest
+// when the first Values are initialized; the result is used for all the
+// Values and reported by EncodedDefault, but not hashed by DefaultsHash.
+func RegisterIntSettingWithDynamicDefault(
+	key, desc string, defaultFn func() int64,
+) *IntSetting {
+	return defaultRegistry.RegisterIntSettingWithDynamicDefault(key, desc, defaultFn)
+}
+
+// RegisterIntSettingWithDynamicDefault implements the Registry interface.
+func (r *registry) RegisterIntSettingWithDynamicDefault(
+	key, desc string, defaultFn func() int64,
+) *IntSetting {
+	setting := &IntSetting{dynamicDefault: &dynamicIntDefault{fn: defaultFn}}
+	r.register(key, desc, setting)
+	return setting
+}
+
 // RegisterPublicIntSetting defines a new setting with type int and makes it public.
 func RegisterPublicIntSetting(key, desc string, defaultValue int64) *IntSetting {
 	s := RegisterValidatedIntSetting(key, desc, defaultValue, nil)
@@ -0,0 +1,61 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+)
+
+// invariant is a constraint on the joint values of several settings; see
+// RegisterInvariant.
+type invariant struct {
+	name  string
+	check func(sv *Values) error
+}
+
+// RegisterInvariant registers a constraint on the joint values of several
+// settings of the default registry, e.g. that a minimum batch size setting
+// does not exceed the corresponding maximum. Such settings can be valid
+// individually and still be misconfigured together. check returns an error
+// if the values of the settings in sv violate the invariant.
+func RegisterInvariant(name string, check func(sv *Values) error) {
+	defaultRegistry.RegisterInvariant(name, check)
+}
+
+// RegisterInvariant implements the Registry interface.
+func (r *registry) RegisterInvariant(name string, check func(sv *Values) error) {
+	for _, inv := range r.invariants {
+		if inv.name == name {
+			panic(fmt.Sprintf("invariant already defined: %s", name))
+		}
+	}
+	r.invariants = append(r.invariants, invariant{name: name, check: check})
+}
+
+// ValidateInvariants checks the values in sv against all the invariants
+// registered with RegisterInvariant, and returns an error for each violated
+// invariant.
+func ValidateInvariants(sv *Values) []error {
+	return sv.getRegistry().ValidateInvariants(sv)
+}
+
+// ValidateInvariants implements the Registry interface.
+func (r *registry) ValidateInvariants(sv *Values) []error {
+	var errs []error
+	for _, inv := range r.invariants {
+		if err := inv.check(sv); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invariant %s violated", inv.name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build settings_lock_audit
+
+package settings
+
+import "sync/atomic"
+
+// lockAuditEnabled enables counting the acquisitions of the locks of Values,
+// which is used to verify that reading a setting does not acquire any locks.
+// Auditing is only enabled when building with the settings_lock_audit tag.
+const lockAuditEnabled = true
+
+var lockAcquisitions int64
+
+func recordLockAcquisition() {
+	atomic.AddInt64(&lockAcquisitions, 1)
+}
+
+// TestingLockAcquisitions returns the number of times locks of any Values
+// have been acquired since the process started.
+func TestingLockAcquisitions() int64 {
+	return atomic.LoadInt64(&lockAcquisitions)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build !settings_lock_audit
+
+package settings
+
+const lockAuditEnabled = false
+
+func recordLockAcquisition() {}
+
+// TestingLockAcquisitions returns 0 since lock auditing is disabled.
+func TestingLockAcquisitions() int64 {
+	return 0
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build settings_lock_audit
+
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadPathIsLockFree verifies that reading primitive settings does not
+// acquire any locks.
+func TestReadPathIsLockFree(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	before := settings.TestingLockAcquisitions()
+	for i := 0; i < 10000; i++ {
+		_ = boolTA.Get(sv)
+		_ = i1A.Get(sv)
+		_ = fA.Get(sv)
+		_ = dA.Get(sv)
+	}
+	require.Equal(t, before, settings.TestingLockAcquisitions())
+
+	// Writes do acquire locks, which shows the audit is working.
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(1), "i"))
+	require.Greater(t, settings.TestingLockAcquisitions(), before)
+}
@@ -10,6 +10,9 @@
 
 package settings
 
+// redactedValue replaces the values of non-reportable settings in reports.
+const redactedValue = "<redacted>"
+
 // MaskedSetting is a pseudo-variable constructed on-the-fly by Lookup
 // when the actual setting is non-reportable.
 type MaskedSetting struct {
@@ -30,7 +33,7 @@ func (s *MaskedSetting) String(sv *Values) string {
 	if st, ok := s.UnderlyingSetting().(*StringSetting); ok && st.String(sv) == "" {
 		return ""
 	}
-	return "<redacted>"
+	return redactedValue
 }
 
 // Visibility returns the visibility setting for the underlying setting.
@@ -43,6 +46,23 @@ func (s *MaskedSetting) Description() string {
 	return s.setting.Description()
 }
 
+// RequiresReason returns whether changes to the underlying setting must be
+// accompanied by a reason.
+func (s *MaskedSetting) RequiresReason() bool {
+	return s.setting.RequiresReason()
+}
+
+// Protected returns whether the underlying setting can only be changed by
+// privileged callers.
+func (s *MaskedSetting) Protected() bool {
+	return s.setting.Protected()
+}
+
+// PreferredUnit returns the display unit of the underlying setting.
+func (s *MaskedSetting) PreferredUnit() string {
+	return s.setting.PreferredUnit()
+}
+
 // Typ returns the short (1 char) string denoting the type of setting.
 func (s *MaskedSetting) Typ() string {
 	return s.setting.Typ()
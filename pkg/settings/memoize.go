@@ -0,0 +1,49 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Memoize returns a function returning the result of compute, which is
+// expected to derive some state from the value of s in sv. compute is only
+// called again once the value of s has changed, or s was touched (see Touch),
+// and the previous result is returned otherwise. The returned function is safe for concurrent use.
+func Memoize(sv *Values, s Setting, compute func() interface{}) func() interface{} {
+	es, ok := s.(extendedSetting)
+	if !ok {
+		panic(errors.AssertionFailedf("cannot memoize a %T", s))
+	}
+	epoch := &sv.notifications[es.getSlotIdx()-1]
+	var mu struct {
+		syncutil.Mutex
+		valid  bool
+		epoch  int64
+		result interface{}
+	}
+	return func() interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		// The epoch is loaded before calling compute so that a change racing
+		// with compute causes it to be called again next time.
+		e := atomic.LoadInt64(epoch)
+		if !mu.valid || mu.epoch != e {
+			mu.result = compute()
+			mu.epoch = e
+			mu.valid = true
+		}
+		return mu.result
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// callbackTracker numbers the commits of changes of the settings of a Values
+// and tracks the commits whose callbacks are still running; see
+// TestingTrackCallbacks.
+type callbackTracker struct {
+	mu syncutil.Mutex
+	// last is the sequence number of the last commit that started.
+	last int64
+	// running holds the sequence numbers of the commits whose callbacks and
+	// dependents are still running.
+	running map[int64]struct{}
+	// finished is closed, and replaced, whenever a commit finishes.
+	finished chan struct{}
+}
+
+// start records that a commit started and returns its sequence number.
+func (t *callbackTracker) start() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last++
+	t.running[t.last] = struct{}{}
+	return t.last
+}
+
+// finish records that the commit with the given sequence number finished.
+func (t *callbackTracker) finish(seq int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.running, seq)
+	close(t.finished)
+	t.finished = make(chan struct{})
+}
+
+// TestingTrackCallbacks starts numbering the commits of changes of the
+// settings in sv, i.e. the changes made through an Updater, a Batch, SetMulti,
+// a transaction or ApplyChanges, and tracking when they finish running their
+// OnChange callbacks, watcher notifications and dependents, until the
+// returned function is called. This lets tests wait for the effects of
+// commits made by other goroutines with TestingWaitForCallbacks. Commits are
+// not tracked otherwise, so that they don't pay for it.
+func TestingTrackCallbacks(sv *Values) (stop func()) {
+	sv.callbackTracker.Store(&callbackTracker{
+		running:  make(map[int64]struct{}),
+		finished: make(chan struct{}),
+	})
+	return func() {
+		sv.callbackTracker.Store((*callbackTracker)(nil))
+	}
+}
+
+// TestingLastCommit returns the sequence number of the last commit of changes
+// of the settings in sv since TestingTrackCallbacks was called, or 0 if there
+// was none. The first commit has sequence number 1.
+func TestingLastCommit(sv *Values) int64 {
+	t, _ := sv.callbackTracker.Load().(*callbackTracker)
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+// TestingWaitForCallbacks blocks until the commit with the given sequence
+// number, and all the commits before it, have finished running their
+// callbacks, and returns an error if that takes longer than timeout. The
+// commit may not have started yet: a test expecting another goroutine to
+// change a setting can capture TestingLastCommit beforehand and wait for the
+// next one. It must be called while TestingTrackCallbacks is in effect, and not
+// from a callback.
+func TestingWaitForCallbacks(sv *Values, seq int64, timeout time.Duration) error {
+	t, _ := sv.callbackTracker.Load().(*callbackTracker)
+	if t == nil {
+		return errors.AssertionFailedf("settings callbacks are not tracked")
+	}
+	deadline := time.After(timeout)
+	for {
+		t.mu.Lock()
+		done := t.last >= seq
+		for s := range t.running {
+			if s <= seq {
+				done = false
+				break
+			}
+		}
+		finished := t.finished
+		t.mu.Unlock()
+		if done {
+			return nil
+		}
+		select {
+		case <-finished:
+		case <-deadline:
+			return errors.Errorf("settings commit %d not finished after %s", seq, timeout)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build settings_read_profile
+
+package settings
+
+import "sync/atomic"
+
+// readProfileEnabled indicates whether reads of setting values are sampled.
+// Build with the settings_read_profile tag to find settings that are read in
+// tight loops; see DumpReadProfile.
+const readProfileEnabled = true
+
+// readProfileSampleEvery is the sampling interval of the read profile: one in
+// every readProfileSampleEvery reads is recorded.
+const readProfileSampleEvery = 1000
+
+var readProfile struct {
+	reads   uint64
+	samples [MaxSettings]int64
+}
+
+// recordRead notes a read of the setting in the given slot.
+func recordRead(slotIdx int) {
+	if atomic.AddUint64(&readProfile.reads, 1)%readProfileSampleEvery == 0 {
+		atomic.AddInt64(&readProfile.samples[slotIdx-1], 1)
+	}
+}
+
+// DumpReadProfile returns the number of sampled reads of each setting that
+// was read since the process started. Each sample stands for roughly
+// readProfileSampleEvery reads.
+//
+// Only available in builds with the settings_read_profile tag; it returns nil
+// otherwise.
+func DumpReadProfile() map[string]int {
+	res := make(map[string]int)
+	for k, s := range defaultRegistry.settings {
+		if n := atomic.LoadInt64(&readProfile.samples[s.getSlotIdx()-1]); n > 0 {
+			res[k] = int(n)
+		}
+	}
+	return res
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build !settings_read_profile
+
+package settings
+
+const readProfileEnabled = false
+
+func recordRead(int) {}
+
+// DumpReadProfile returns nil; reads are only sampled in builds with the
+// settings_read_profile tag.
+func DumpReadProfile() map[string]int {
+	return nil
+}
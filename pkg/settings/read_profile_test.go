@@ -0,0 +1,35 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build settings_read_profile
+
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProfile(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	before := settings.DumpReadProfile()
+	for i := 0; i < 100000; i++ {
+		_ = i1A.Get(sv)
+	}
+	after := settings.DumpReadProfile()
+	// Reads are sampled, and other reads may interleave with ours, so only
+	// check that the bulk of our reads were attributed to the setting.
+	require.Greater(t, after["i.1"]-before["i.1"], 50)
+	require.Equal(t, before["i.2"], after["i.2"])
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+// MarkReady marks the Values as ready, i.e. as reflecting the settings
+// persisted for the cluster rather than only their defaults, and runs the
+// functions registered with OnReadyOnce. Only the first call has any effect.
+func (sv *Values) MarkReady() {
+	sv.readyMu.Lock()
+	if sv.readyMu.ready {
+		sv.readyMu.Unlock()
+		return
+	}
+	sv.readyMu.ready = true
+	onReady := sv.readyMu.onReady
+	sv.readyMu.onReady = nil
+	sv.readyMu.Unlock()
+
+	for _, fn := range onReady {
+		fn()
+	}
+}
+
+// IsReady returns whether MarkReady was called.
+func (sv *Values) IsReady() bool {
+	sv.readyMu.Lock()
+	defer sv.readyMu.Unlock()
+	return sv.readyMu.ready
+}
+
+// OnReadyOnce arranges for fn to be called exactly once, after sv is marked
+// ready by MarkReady. If sv is already ready, fn is called immediately.
+func OnReadyOnce(sv *Values, fn func()) {
+	sv.readyMu.Lock()
+	if !sv.readyMu.ready {
+		sv.readyMu.onReady = append(sv.readyMu.onReady, fn)
+		sv.readyMu.Unlock()
+		return
+	}
+	sv.readyMu.Unlock()
+	fn()
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import "context"
+
+type reasonKey struct{}
+
+// ContextWithReason returns a context carrying the given reason for changes
+// to settings, which Updaters made with NewUpdaterWithContext record for the
+// changes they are not given an explicit reason for.
+func ContextWithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonKey{}, reason)
+}
+
+// ReasonFromContext returns the reason attached to ctx by ContextWithReason,
+// if any.
+func ReasonFromContext(ctx context.Context) string {
+	reason, _ := ctx.Value(reasonKey{}).(string)
+	return reason
+}
+
+// NewUpdaterWithContext makes an Updater for the settings in sv whose changes
+// default to the reason attached to ctx by ContextWithReason: Set and
+// SetWithReason with an empty reason behave as SetWithReason with the reason
+// from the context. This saves code applying many changes for the same
+// reason, e.g. a migration, from passing the reason to every call.
+func NewUpdaterWithContext(ctx context.Context, sv *Values) Updater {
+	u := sv.getRegistry().MakeUpdater(sv).(updater)
+	u.defaultReason = ReasonFromContext(ctx)
+	return u
+}
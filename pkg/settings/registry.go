@@ -11,31 +11,148 @@
 package settings
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"reflect"
 	"sort"
+	"time"
 	"unicode"
 	"unicode/utf8"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
 )
 
+// Registry is a set of settings, along with the means to look them up and to
+// update their values.
+//
+// The settings registered through the package-level Register functions belong
+// to the default registry, which is also the one used by the package-level
+// functions like Lookup and NewUpdater. NewRegistry makes a registry that is
+// independent of the default one, e.g. so that tests can define settings
+// without affecting each other.
+type Registry interface {
+	// Lookup returns a Setting by name; see the package-level Lookup.
+	Lookup(name string, purpose LookupPurpose) (Setting, bool)
+	// Keys returns a sorted string array with all the known keys.
+	Keys() []string
+	// Filter returns the settings that satisfy a predicate; see the
+	// package-level Filter.
+	Filter(pred func(Setting) bool) []Setting
+	// NumRegisteredSettings returns the number of registered settings.
+	NumRegisteredSettings() int
+	// ApproxBytes returns the approximate memory footprint of the registry.
+	ApproxBytes() int
+	// DefaultsHash returns a hash of the defaults of the settings; see the
+	// package-level DefaultsHash.
+	DefaultsHash() string
+	// InitValues is the equivalent of Values.Init for the settings of this
+	// registry. A Values instance can only be used with the registry that
+	// initialized it.
+	InitValues(sv *Values, opaque interface{})
+	// MakeUpdater makes an Updater for the settings of this registry.
+	MakeUpdater(sv *Values) Updater
+
+	RegisterBoolSetting(key, desc string, defaultValue bool) *BoolSetting
+	RegisterIntSetting(key, desc string, defaultValue int64) *IntSetting
+	RegisterIntSettingWithDynamicDefault(key, desc string, defaultFn func() int64) *IntSetting
+	RegisterFloatSetting(key, desc string, defaultValue float64) *FloatSetting
+	RegisterDurationSetting(key, desc string, defaultValue time.Duration) *DurationSetting
+	RegisterStringSetting(key, desc string, defaultValue string) *StringSetting
+
+	// RegisterAliasWithTransform registers an old key of a setting; see the
+	// package-level RegisterAliasWithTransform.
+	RegisterAliasWithTransform(
+		oldKey, newKey string, transform func(oldEncoded string) (newEncoded string, err error),
+	)
+
+	// RegisterInvariant registers a constraint on the joint values of several
+	// settings; see the package-level RegisterInvariant.
+	RegisterInvariant(name string, check func(sv *Values) error)
+	// ValidateInvariants returns an error for each registered invariant that
+	// the values in sv violate.
+	ValidateInvariants(sv *Values) []error
+}
+
 // registry contains all defined settings, their types and default values.
 //
 // The registry does not store the current values of the settings; those are
 // stored separately in Values, allowing multiple independent instances
 // of each setting in the registry.
+type registry struct {
+	// mu serializes the registrations, including those made by the
+	// TestingRegister functions, and unregister. The settings are otherwise
+	// read without it, so the TestingRegister functions and unregister must
+	// not race with other uses of the registry.
+	mu syncutil.Mutex
+
+	settings   map[string]extendedSetting
+	invariants []invariant
+	// aliases maps old keys of settings to the settings; see
+	// RegisterAliasWithTransform.
+	aliases map[string]alias
+	// freeSlots are the slots of the settings unregistered through unregister,
+	// which are reused before allocating new ones.
+	freeSlots []int
+	// scoped tracks the settings registered through the TestingRegister
+	// functions; see unregister.
+	scoped struct {
+		// count is the number of such settings currently registered.
+		count int
+		// values are the Values initialized while count was positive.
+		values []*Values
+	}
+	// slotCallbacks counts, for each slot, the OnChange callbacks, watchers
+	// and dependents installed for it in any Values, so that unregister can
+	// tell whether the slot can be reused. Accessed atomically.
+	slotCallbacks [MaxSettings]int32
+}
+
+var _ Registry = &registry{}
+
+// defaultRegistry contains the settings registered through the package-level
+// functions.
 //
-// registry should never be mutated after creation (except in tests), as it is
-// read concurrently by different callers.
-var registry = make(map[string]extendedSetting)
+// defaultRegistry should never be mutated after creation (except in tests), as
+// it is read concurrently by different callers.
+var defaultRegistry = newRegistry()
+
+// NewRegistry makes an empty Registry, independent of the default one.
+func NewRegistry() Registry {
+	return newRegistry()
+}
+
+func newRegistry() *registry {
+	return &registry{settings: make(map[string]extendedSetting)}
+}
 
 // TestingSaveRegistry can be used in tests to save/restore the current
-// contents of the registry.
+// contents of the default registry.
 func TestingSaveRegistry() func() {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
 	var origRegistry = make(map[string]extendedSetting)
-	for k, v := range registry {
+	for k, v := range defaultRegistry.settings {
 		origRegistry[k] = v
 	}
+	origInvariants := defaultRegistry.invariants
+	origFreeSlots := append([]int(nil), defaultRegistry.freeSlots...)
+	var origAliases map[string]alias
+	if defaultRegistry.aliases != nil {
+		origAliases = make(map[string]alias)
+		for k, v := range defaultRegistry.aliases {
+			origAliases[k] = v
+		}
+	}
 	return func() {
-		registry = origRegistry
+		defaultRegistry.mu.Lock()
+		defer defaultRegistry.mu.Unlock()
+		defaultRegistry.settings = origRegistry
+		defaultRegistry.invariants = origInvariants
+		defaultRegistry.aliases = origAliases
+		defaultRegistry.freeSlots = origFreeSlots
 	}
 }
 
@@ -75,12 +192,19 @@ var retiredSettings = map[string]struct{}{
 	"sql.distsql.interleaved_joins.enabled": {},
 }
 
-// register adds a setting to the registry.
+// register adds a setting to the default registry.
 func register(key, desc string, s extendedSetting) {
+	defaultRegistry.register(key, desc, s)
+}
+
+// register adds a setting to the registry.
+func (r *registry) register(key, desc string, s extendedSetting) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if _, ok := retiredSettings[key]; ok {
 		panic(fmt.Sprintf("cannot reuse previously defined setting name: %s", key))
 	}
-	if _, ok := registry[key]; ok {
+	if _, ok := r.settings[key]; ok {
 		panic(fmt.Sprintf("setting already defined: %s", key))
 	}
 	if len(desc) == 0 {
@@ -90,18 +214,51 @@ func register(key, desc string, s extendedSetting) {
 		panic(fmt.Sprintf("setting descriptions should start with a lowercase letter: %q", desc))
 	}
 	s.setDescription(desc)
-	registry[key] = s
-	s.setSlotIdx(len(registry))
+	r.settings[key] = s
+	if n := len(r.freeSlots); n > 0 {
+		s.setSlotIdx(r.freeSlots[n-1])
+		r.freeSlots = r.freeSlots[:n-1]
+		return
+	}
+	s.setSlotIdx(len(r.settings))
 }
 
 // NumRegisteredSettings returns the number of registered settings.
-func NumRegisteredSettings() int { return len(registry) }
+func NumRegisteredSettings() int { return defaultRegistry.NumRegisteredSettings() }
+
+// NumRegisteredSettings implements the Registry interface.
+func (r *registry) NumRegisteredSettings() int { return len(r.settings) }
+
+// ApproxRegistryBytes returns the approximate memory footprint of the default
+// registry, e.g. to detect settings being registered in a loop.
+func ApproxRegistryBytes() int { return defaultRegistry.ApproxBytes() }
+
+// ApproxBytes implements the Registry interface. It accounts for the keys,
+// descriptions and structs of the settings, along with the names of the
+// invariants, but not for the memory referenced by individual settings, like
+// the values of enum settings.
+func (r *registry) ApproxBytes() int {
+	const entryOverhead = int(unsafe.Sizeof("") + unsafe.Sizeof(extendedSetting(nil)))
+	n := int(unsafe.Sizeof(*r))
+	for k, s := range r.settings {
+		n += entryOverhead + len(k) + len(s.Description()) + int(reflect.TypeOf(s).Elem().Size())
+	}
+	for _, inv := range r.invariants {
+		n += int(unsafe.Sizeof(inv)) + len(inv.name)
+	}
+	return n
+}
 
 // Keys returns a sorted string array with all the known keys.
 func Keys() (res []string) {
-	res = make([]string, 0, len(registry))
-	for k := range registry {
-		if registry[k].isRetired() {
+	return defaultRegistry.Keys()
+}
+
+// Keys implements the Registry interface.
+func (r *registry) Keys() (res []string) {
+	res = make([]string, 0, len(r.settings))
+	for k := range r.settings {
+		if r.settings[k].isRetired() {
 			continue
 		}
 		res = append(res, k)
@@ -114,7 +271,12 @@ func Keys() (res []string) {
 // For non-reportable setting, it instantiates a MaskedSetting
 // to masquerade for the underlying setting.
 func Lookup(name string, purpose LookupPurpose) (Setting, bool) {
-	v, ok := registry[name]
+	return defaultRegistry.Lookup(name, purpose)
+}
+
+// Lookup implements the Registry interface.
+func (r *registry) Lookup(name string, purpose LookupPurpose) (Setting, bool) {
+	v, ok := r.settings[name]
 	var setting Setting = v
 	if ok && purpose == LookupForReporting && !v.isReportable() {
 		setting = &MaskedSetting{setting: v}
@@ -122,6 +284,88 @@ func Lookup(name string, purpose LookupPurpose) (Setting, bool) {
 	return setting, ok
 }
 
+// Filter returns, sorted by key, the settings that satisfy pred, which is
+// called once for each known setting. Like with LookupForLocalAccess, the
+// settings are not masked.
+func Filter(pred func(Setting) bool) []Setting {
+	return defaultRegistry.Filter(pred)
+}
+
+// Filter implements the Registry interface.
+func (r *registry) Filter(pred func(Setting) bool) []Setting {
+	var res []Setting
+	for _, k := range r.Keys() {
+		if s := r.settings[k]; pred(s) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// DefaultsHash returns a hash over the key, type and encoded default value of
+// every registered setting. It only depends on the settings compiled into the
+// binary, so that nodes can compare it to detect binaries whose defaults differ
+// from each other, e.g. in upgrade preflight checks. Defaults overridden at
+// runtime, e.g. through ApplyDefaultsFromFile, are not taken into account, and
+// neither are the values of defaults computed at runtime, which are hashed as
+// dynamicDefaultHashToken.
+func DefaultsHash() string {
+	return defaultRegistry.DefaultsHash()
+}
+
+// DefaultsHash implements the Registry interface.
+func (r *registry) DefaultsHash() string {
+	keys := make([]string, 0, len(r.settings))
+	for k := range r.settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		s := r.settings[k]
+		encodedDefault := s.EncodedDefault()
+		if i, ok := s.(*IntSetting); ok && i.dynamicDefault != nil {
+			encodedDefault = dynamicDefaultHashToken
+		}
+		fmt.Fprintf(h, "%q %q %q\n", k, s.Typ(), encodedDefault)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// dynamicDefaultHashToken stands in DefaultsHash for the defaults computed by
+// the functions passed to RegisterIntSettingWithDynamicDefault, which can
+// differ between nodes running the same binary.
+const dynamicDefaultHashToken = "<dynamic>"
+
+// minLintDescriptionLength is the length below which Lint considers the
+// description of a setting too short to be useful.
+const minLintDescriptionLength = 10
+
+// Lint returns an error for each registered setting, other than retired ones,
+// whose description does not meet our documentation standards: descriptions
+// must be at least a few words long and end with punctuation.
+func Lint() []error {
+	return defaultRegistry.lint()
+}
+
+func (r *registry) lint() []error {
+	var errs []error
+	for _, k := range r.Keys() {
+		desc := r.settings[k].Description()
+		if desc == "" {
+			errs = append(errs, errors.Errorf("setting '%s' has no description", k))
+			continue
+		}
+		if len(desc) < minLintDescriptionLength {
+			errs = append(errs, errors.Errorf("setting '%s' has a description that is too short: %q", k, desc))
+		}
+		if last, _ := utf8.DecodeLastRuneInString(desc); !unicode.IsPunct(last) {
+			errs = append(errs, errors.Errorf("setting '%s' has a description that does not end with punctuation: %q", k, desc))
+		}
+	}
+	return errs
+}
+
 // LookupPurpose indicates what is being done with the setting.
 type LookupPurpose int
 
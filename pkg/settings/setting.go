@@ -14,8 +14,10 @@ import (
 	"fmt"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // MaxSettings is the maximum number of settings that the system supports.
@@ -30,7 +32,7 @@ type Values struct {
 	container valuesContainer
 
 	overridesMu struct {
-		syncutil.Mutex
+		auditedMutex
 		// defaultOverrides maintains the set of overridden default values (see
 		// Override()).
 		defaultOverrides valuesContainer
@@ -39,23 +41,129 @@ type Values struct {
 	}
 
 	changeMu struct {
-		syncutil.Mutex
+		auditedMutex
 		// NB: any in place modification to individual slices must also hold the
 		// lock, e.g. if we ever add RemoveOnChange or something.
 		onChange [MaxSettings][]func()
+		// watchers are notified after the onChange callbacks; see watch. The
+		// slices are replaced rather than modified in place when a watcher is
+		// removed.
+		watchers [MaxSettings][]*watcher
 	}
+
+	// onChangeLatency tracks how long the onChange callbacks take to run, to
+	// help identify slow callbacks stalling settings updates.
+	onChangeLatency struct {
+		// lastNanos is the duration of the most recent run of the callbacks of a
+		// setting. Accessed atomically.
+		lastNanos int64
+		// hook stores an OnChangeLatencyHook.
+		hook atomic.Value
+	}
+
+	// reasons stores, for each setting last changed through
+	// Updater.SetWithReason, a valueWithReason; see ValueAndReason.
+	reasons [MaxSettings]atomic.Value
+
+	// frozenMu holds the keys frozen by FreezeKeys.
+	frozenMu struct {
+		auditedMutex
+		// keys maps each frozen key to the freezes including it, in the order
+		// they were made.
+		keys map[string][]*keyFreeze
+	}
+
+	// readyMu tracks whether the Values were marked ready; see MarkReady.
+	readyMu struct {
+		auditedMutex
+		ready bool
+		// onReady are the functions to run once the Values are marked ready.
+		onReady []func()
+	}
+
+	// epochs counts, for each setting, how many times its value changed.
+	// Accessed atomically.
+	epochs [MaxSettings]int64
+	// notifications counts, for each setting, how many times its consumers
+	// were notified of a change, which includes the notifications made by
+	// Touch without changing the value. Accessed atomically.
+	notifications [MaxSettings]int64
+
+	// writeMu serializes the changes made through Updaters, Batches, SetMulti
+	// and transactions with each other and with FreezeKeys, so that the changes
+	// validated while holding it are applied under the same conditions; see
+	// commit.
+	writeMu auditedMutex
+
+	// callbackTracker stores the *callbackTracker tracking the commits, if
+	// any; see TestingTrackCallbacks.
+	callbackTracker atomic.Value
+
+	// dependents holds the settings registered with RegisterDependent.
+	dependents struct {
+		auditedMutex
+		// count is the length of list. Accessed atomically, so that changes
+		// don't take the mutex unless there are dependents.
+		count int32
+		list  []*dependent
+		// changed collects the parents changed by the commit in progress, if
+		// any; see commit.
+		changed map[int]struct{}
+	}
+
+	// changeLog records the changes made through Updaters; see
+	// ExportChangeLog.
+	changeLog struct {
+		auditedMutex
+		records []ChangeRecord
+	}
+
+	// testingCapture stores the *changeCapture recording all changes, if any;
+	// see TestingCaptureChanges.
+	testingCapture atomic.Value
 	// opaque is an arbitrary object that can be set by a higher layer to make it
 	// accessible from certain callbacks (like state machine transformers).
 	opaque interface{}
+	// registry is the registry that initialized the Values; nil means the
+	// default registry.
+	registry *registry
+}
+
+// auditedMutex is the mutex used by Values. Its acquisitions are counted when
+// building with the settings_lock_audit tag.
+type auditedMutex struct {
+	syncutil.Mutex
+}
+
+// Lock acquires the mutex.
+func (m *auditedMutex) Lock() {
+	if lockAuditEnabled {
+		recordLockAcquisition()
+	}
+	m.Mutex.Lock()
 }
 
 type valuesContainer struct {
-	intVals     [MaxSettings]int64
+	intVals [MaxSettings]int64
+	// genericVals holds genericVal wrappers, so that a slot can hold values of
+	// different types over time, e.g. once it is reused by another setting
+	// (see unregister), which an atomic.Value doesn't allow otherwise.
 	genericVals [MaxSettings]atomic.Value
 }
 
+// genericVal wraps the values stored in valuesContainer.genericVals.
+type genericVal struct {
+	v interface{}
+}
+
 func (c *valuesContainer) setGenericVal(slotIdx int, newVal interface{}) {
-	c.genericVals[slotIdx].Store(newVal)
+	c.genericVals[slotIdx].Store(genericVal{v: newVal})
+}
+
+// loadGenericVal returns the value stored by setGenericVal, if any.
+func (c *valuesContainer) loadGenericVal(slotIdx int) interface{} {
+	gv, _ := c.genericVals[slotIdx].Load().(genericVal)
+	return gv.v
 }
 
 func (c *valuesContainer) setInt64Val(slotIdx int, newVal int64) bool {
@@ -93,10 +201,25 @@ var TestOpaque interface{} = testOpaqueType{}
 //
 // The opaque argument can be retrieved later via Opaque().
 func (sv *Values) Init(opaque interface{}) {
+	defaultRegistry.InitValues(sv, opaque)
+}
+
+// InitValues implements the Registry interface.
+func (r *registry) InitValues(sv *Values, opaque interface{}) {
 	sv.opaque = opaque
-	for _, s := range registry {
+	sv.registry = r
+	for _, s := range r.settings {
 		s.setToDefault(sv)
 	}
+	r.trackScoped(sv)
+}
+
+// getRegistry returns the registry of the settings stored in sv.
+func (sv *Values) getRegistry() *registry {
+	if sv.registry == nil {
+		return defaultRegistry
+	}
+	return sv.registry
 }
 
 // Opaque returns the argument passed to Init.
@@ -104,21 +227,115 @@ func (sv *Values) Opaque() interface{} {
 	return sv.opaque
 }
 
+// OnChangeLatencyHook is called after each onChange callback with the key of
+// the changed setting, the index of the callback in the order the callbacks
+// were installed, and the time the callback took to run.
+type OnChangeLatencyHook func(key string, callbackIdx int, d time.Duration)
+
+// SetOnChangeLatencyHook installs a hook timing the individual onChange
+// callbacks, e.g. to feed a histogram. Passing nil removes the hook.
+func (sv *Values) SetOnChangeLatencyHook(fn OnChangeLatencyHook) {
+	sv.onChangeLatency.hook.Store(fn)
+}
+
+// LastOnChangeDuration returns how long the onChange callbacks took to run
+// the last time a setting with callbacks changed.
+func (sv *Values) LastOnChangeDuration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&sv.onChangeLatency.lastNanos))
+}
+
 func (sv *Values) settingChanged(slotIdx int) {
+	atomic.AddInt64(&sv.epochs[slotIdx-1], 1)
+	if c, _ := sv.testingCapture.Load().(*changeCapture); c != nil {
+		c.record(sv, slotIdx)
+	}
+	sv.notifyChange(slotIdx)
+}
+
+// notifyChange notifies the consumers of the setting in the given slot of a
+// change: it notifies its watchers and runs its onChange callbacks.
+func (sv *Values) notifyChange(slotIdx int) {
+	atomic.AddInt64(&sv.notifications[slotIdx-1], 1)
+	// The dependents are recomputed after the callbacks have run.
+	defer sv.parentChanged(slotIdx)
+
 	sv.changeMu.Lock()
 	funcs := sv.changeMu.onChange[slotIdx-1]
+	watchers := sv.changeMu.watchers[slotIdx-1]
 	sv.changeMu.Unlock()
-	for _, fn := range funcs {
-		fn()
+	for _, w := range watchers {
+		w.notify()
 	}
+	if len(funcs) == 0 {
+		return
+	}
+	hook, _ := sv.onChangeLatency.hook.Load().(OnChangeLatencyHook)
+	var key string
+	if hook != nil {
+		key = sv.getRegistry().keyForSlot(slotIdx)
+	}
+	start := timeutil.Now()
+	for i, fn := range funcs {
+		if hook == nil {
+			sv.runOnChange(slotIdx, fn)
+			continue
+		}
+		fnStart := timeutil.Now()
+		sv.runOnChange(slotIdx, fn)
+		hook(key, i, timeutil.Since(fnStart))
+	}
+	atomic.StoreInt64(&sv.onChangeLatency.lastNanos, int64(timeutil.Since(start)))
+}
+
+// commit runs fn, which changes the settings in sv, while holding writeMu.
+// The dependents of the settings changed by fn are then recomputed, once
+// each, after releasing writeMu; see RegisterDependent.
+func (sv *Values) commit(fn func() error) error {
+	sv.writeMu.Lock()
+	if t, _ := sv.callbackTracker.Load().(*callbackTracker); t != nil {
+		defer t.finish(t.start())
+	}
+	sv.beginDependents()
+	err := fn()
+	changed := sv.endDependents()
+	sv.writeMu.Unlock()
+	sv.recomputeDependents(changed)
+	return err
+}
+
+// scratchCopy returns a copy of the values of the settings in sv, without
+// their callbacks or any other state, so that changes can be validated by
+// applying them to the copy first.
+func (sv *Values) scratchCopy() *Values {
+	scratch := &Values{opaque: sv.opaque, registry: sv.registry}
+	for _, s := range sv.getRegistry().settings {
+		scratch.copySlotFrom(sv, s.getSlotIdx())
+	}
+	return scratch
+}
+
+// keyForSlot returns the key of the setting using the given slot.
+func (r *registry) keyForSlot(slotIdx int) string {
+	for k, v := range r.settings {
+		if v.getSlotIdx() == slotIdx {
+			return k
+		}
+	}
+	return ""
 }
 
 func (c *valuesContainer) getInt64(slotIdx int) int64 {
+	if readProfileEnabled {
+		recordRead(slotIdx)
+	}
 	return atomic.LoadInt64(&c.intVals[slotIdx-1])
 }
 
 func (c *valuesContainer) getGeneric(slotIdx int) interface{} {
-	return c.genericVals[slotIdx-1].Load()
+	if readProfileEnabled {
+		recordRead(slotIdx)
+	}
+	return c.loadGenericVal(slotIdx - 1)
 }
 
 func (sv *Values) setInt64(slotIdx int, newVal int64) {
@@ -127,6 +344,15 @@ func (sv *Values) setInt64(slotIdx int, newVal int64) {
 	}
 }
 
+// copySlotFrom copies the value in the given slot of other into sv, without
+// running any onChange callbacks.
+func (sv *Values) copySlotFrom(other *Values, slotIdx int) {
+	sv.container.setInt64Val(slotIdx-1, atomic.LoadInt64(&other.container.intVals[slotIdx-1]))
+	if v := other.container.loadGenericVal(slotIdx - 1); v != nil {
+		sv.container.setGenericVal(slotIdx-1, v)
+	}
+}
+
 // setDefaultOverrideInt64 overrides the default value for the respective
 // setting to newVal.
 func (sv *Values) setDefaultOverrideInt64(slotIdx int, newVal int64) {
@@ -136,6 +362,15 @@ func (sv *Values) setDefaultOverrideInt64(slotIdx int, newVal int64) {
 	sv.setDefaultOverrideLocked(slotIdx)
 }
 
+// setDefaultOverrideGeneric overrides the default value for the respective
+// setting to newVal.
+func (sv *Values) setDefaultOverrideGeneric(slotIdx int, newVal interface{}) {
+	sv.overridesMu.Lock()
+	defer sv.overridesMu.Unlock()
+	sv.overridesMu.defaultOverrides.setGenericVal(slotIdx-1, newVal)
+	sv.setDefaultOverrideLocked(slotIdx)
+}
+
 // setDefaultOverrideLocked marks slotIdx-1 as having an overridden default value.
 func (sv *Values) setDefaultOverrideLocked(slotIdx int) {
 	if sv.overridesMu.setOverrides == nil {
@@ -144,12 +379,20 @@ func (sv *Values) setDefaultOverrideLocked(slotIdx int) {
 	sv.overridesMu.setOverrides[slotIdx-1] = struct{}{}
 }
 
+// clearDefaultOverride removes the default override for the respective
+// setting, if any.
+func (sv *Values) clearDefaultOverride(slotIdx int) {
+	sv.overridesMu.Lock()
+	defer sv.overridesMu.Unlock()
+	delete(sv.overridesMu.setOverrides, slotIdx-1)
+}
+
 // getDefaultOverrides checks whether there's a default override for slotIdx-1.
 // If there isn't, the first ret val is false. Otherwise, the first ret val is
-// true, the second is the int64 override and the last is a pointer to the
-// generic value override. Callers are expected to only use the override value
+// true, the second is the int64 override and the last is the generic value
+// override. Callers are expected to only use the override value
 // corresponding to their setting type.
-func (sv *Values) getDefaultOverride(slotIdx int) (bool, int64, *atomic.Value) {
+func (sv *Values) getDefaultOverride(slotIdx int) (bool, int64, interface{}) {
 	slotIdx--
 	sv.overridesMu.Lock()
 	defer sv.overridesMu.Unlock()
@@ -158,7 +401,7 @@ func (sv *Values) getDefaultOverride(slotIdx int) (bool, int64, *atomic.Value) {
 	}
 	return true,
 		sv.overridesMu.defaultOverrides.intVals[slotIdx],
-		&sv.overridesMu.defaultOverrides.genericVals[slotIdx]
+		sv.overridesMu.defaultOverrides.loadGenericVal(slotIdx)
 }
 
 func (sv *Values) setGeneric(slotIdx int, newVal interface{}) {
@@ -181,6 +424,7 @@ func (sv *Values) setOnChange(slotIdx int, fn func()) {
 	sv.changeMu.Lock()
 	sv.changeMu.onChange[slotIdx-1] = append(sv.changeMu.onChange[slotIdx-1], fn)
 	sv.changeMu.Unlock()
+	sv.getRegistry().addSlotCallbacks(slotIdx, 1)
 }
 
 // Setting is a descriptor for each setting; once it is initialized, it is
@@ -193,6 +437,15 @@ type Setting interface {
 	String(sv *Values) string
 	Description() string
 	Visibility() Visibility
+	// RequiresReason returns whether changes to the setting must be
+	// accompanied by a reason.
+	RequiresReason() bool
+	// Protected returns whether the setting can only be changed by privileged
+	// callers.
+	Protected() bool
+	// PreferredUnit returns the unit the values of the setting are displayed
+	// in, or an empty string if the setting has no fixed display unit.
+	PreferredUnit() string
 }
 
 // WritableSetting is the exported interface of non-masked settings.
@@ -251,6 +504,8 @@ type common struct {
 	slotIdx       int
 	nonReportable bool
 	retired       bool
+	requireReason bool
+	protected     bool
 }
 
 func (i *common) isRetired() bool {
@@ -282,6 +537,25 @@ func (i common) Visibility() Visibility {
 	return i.visibility
 }
 
+// RequiresReason returns whether changes to the setting must be accompanied by
+// a reason. See SetRequireReason.
+func (i common) RequiresReason() bool {
+	return i.requireReason
+}
+
+// Protected returns whether the setting can only be changed by privileged
+// callers. See SetProtected.
+func (i common) Protected() bool {
+	return i.protected
+}
+
+// PreferredUnit returns an empty string; settings with a fixed display unit,
+// such as ByteSizeSettings registered with RegisterByteSizeSettingWithUnit,
+// override it.
+func (i common) PreferredUnit() string {
+	return ""
+}
+
 func (i common) isReportable() bool {
 	return !i.nonReportable
 }
@@ -309,6 +583,20 @@ func (i *common) SetVisibility(v Visibility) {
 	i.visibility = v
 }
 
+// SetRequireReason marks the setting as sensitive: Updaters, apart from those
+// made by NewRefreshUpdater, reject changes to it that do not document why they
+// are being made.
+func (i *common) SetRequireReason() {
+	i.requireReason = true
+}
+
+// SetProtected marks the setting as only changeable by privileged callers,
+// e.g. superusers: the Updaters made by NewUnprivilegedUpdater reject changes
+// to it.
+func (i *common) SetProtected() {
+	i.protected = true
+}
+
 // SetRetired marks the setting as obsolete. It also hides
 // it from the output of SHOW CLUSTER SETTINGS.
 func (i *common) SetRetired() {
@@ -318,7 +606,8 @@ func (i *common) SetRetired() {
 
 // SetOnChange installs a callback to be called when a setting's value changes.
 // `fn` should avoid doing long-running or blocking work as it is called on the
-// goroutine which handles all settings updates.
+// goroutine which handles all settings updates. It is called while the change
+// is being applied, so it must not change settings in sv itself.
 func (i *common) SetOnChange(sv *Values, fn func()) {
 	sv.setOnChange(i.slotIdx, fn)
 }
@@ -329,6 +618,49 @@ type numericSetting interface {
 	set(sv *Values, i int64) error
 }
 
+// Change describes a change of the value of a setting.
+type Change struct {
+	Key string
+	// Encoded is the encoded new value of the setting.
+	Encoded string
+}
+
+// TestingCaptureChanges records all subsequent changes of the settings in sv,
+// in the order in which they happen, until the returned function is called.
+// This allows tests to assert that exactly the expected settings changed. If
+// settings may be changed by other goroutines, the changes must only be read
+// after calling restore, which waits for the changes being recorded.
+func TestingCaptureChanges(sv *Values) (changes *[]Change, restore func()) {
+	c := &changeCapture{changes: &[]Change{}}
+	prev, _ := sv.testingCapture.Load().(*changeCapture)
+	sv.testingCapture.Store(c)
+	return c.changes, func() {
+		sv.testingCapture.Store(prev)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.stopped = true
+	}
+}
+
+// changeCapture records changes for TestingCaptureChanges.
+type changeCapture struct {
+	mu      syncutil.Mutex
+	stopped bool
+	changes *[]Change
+}
+
+// record records the change of the setting in the given slot of sv.
+func (c *changeCapture) record(sv *Values, slotIdx int) {
+	r := sv.getRegistry()
+	key := r.keyForSlot(slotIdx)
+	change := Change{Key: key, Encoded: r.settings[key].Encoded(sv)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.stopped {
+		*c.changes = append(*c.changes, change)
+	}
+}
+
 // TestingIsReportable is used in testing for reportability.
 func TestingIsReportable(s Setting) bool {
 	if _, ok := s.(*MaskedSetting); ok {
@@ -346,3 +678,55 @@ func TestingIsReportable(s Setting) bool {
 func AdminOnly(name string) bool {
 	return !strings.HasPrefix(name, "sql.defaults.")
 }
+
+// MetricName returns the canonical name under which the value of the setting
+// with the given key is exported as a metric, e.g. kv.range.size becomes
+// cockroach_setting_kv_range_size.
+func MetricName(key string) string {
+	return "cockroach_setting_" + metricNameReplacer.Replace(strings.ToLower(key))
+}
+
+var metricNameReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// valueWithReason is the encoded value a setting was changed to, along with
+// the reason given for the change.
+type valueWithReason struct {
+	encoded string
+	reason  string
+}
+
+// clearStaleReason forgets the reason recorded for the last change of s if
+// the value of s has changed since.
+func (sv *Values) clearStaleReason(s extendedSetting) {
+	r := &sv.reasons[s.getSlotIdx()-1]
+	if vr, _ := r.Load().(valueWithReason); vr.reason != "" && vr.encoded != s.Encoded(sv) {
+		r.Store(valueWithReason{})
+	}
+}
+
+// ValueAndReason returns the encoded value of the setting with the given key
+// in sv, along with the reason given when it was changed to that value
+// through Updater.SetWithReason. The reason is empty if the value was not set
+// with a reason. The value and reason are read consistently: the returned
+// reason always pertains to the returned value.
+func ValueAndReason(sv *Values, key string) (value string, reason string, ok bool) {
+	s, ok := sv.getRegistry().settings[key]
+	if !ok {
+		return "", "", false
+	}
+	r := &sv.reasons[s.getSlotIdx()-1]
+	for {
+		vr, _ := r.Load().(valueWithReason)
+		value = s.Encoded(sv)
+		// Retry if a concurrent SetWithReason recorded a new reason while we
+		// were reading the value.
+		if again, _ := r.Load().(valueWithReason); again != vr {
+			continue
+		}
+		if vr.encoded != value {
+			// The value was changed without a reason.
+			return value, "", true
+		}
+		return value, vr.reason, true
+	}
+}
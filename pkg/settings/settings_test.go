@@ -13,7 +13,14 @@ package settings_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 	"unicode"
@@ -689,6 +696,8 @@ func TestIsReportable(t *testing.T) {
 }
 
 func TestOnChangeWithMaxSettings(t *testing.T) {
+	defer settings.TestingSaveRegistry()()
+
 	// Register MaxSettings settings to ensure that no errors occur.
 	maxName, err := batchRegisterSettings(t, t.Name(), settings.MaxSettings-settings.NumRegisteredSettings())
 	if err != nil {
@@ -788,3 +797,2052 @@ func TestOverride(t *testing.T) {
 	u.ResetRemaining()
 	require.Equal(t, 42.0, overrideFloat.Get(sv))
 }
+
+var resetRetired = settings.RegisterIntSetting("reset.retired", "desc", 1)
+var resetInt = settings.RegisterIntSetting("reset.int", "desc", 2)
+
+func init() {
+	resetRetired.SetRetired()
+}
+
+func TestResetPrefix(t *testing.T) {
+	resetOtherInt, unregister := settings.TestingRegisterInt(t, "resetother.int", "desc", 3)
+	defer unregister()
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("reset.retired", settings.EncodeInt(10), "i"))
+	require.NoError(t, u.Set("reset.int", settings.EncodeInt(20), "i"))
+	require.NoError(t, u.Set("resetother.int", settings.EncodeInt(30), "i"))
+
+	// Only the non-retired setting under the prefix reverts to its default.
+	u = settings.NewUpdater(sv)
+	require.NoError(t, u.ResetPrefix("reset.", false /* includeRetired */))
+	require.Equal(t, int64(10), resetRetired.Get(sv))
+	require.Equal(t, int64(2), resetInt.Get(sv))
+	require.Equal(t, int64(30), resetOtherInt.Get(sv))
+
+	require.NoError(t, u.ResetPrefix("reset.", true /* includeRetired */))
+	require.Equal(t, int64(1), resetRetired.Get(sv))
+	require.Equal(t, int64(30), resetOtherInt.Get(sv))
+
+	require.EqualError(t, u.ResetPrefix("dne.", true /* includeRetired */),
+		"no settings match prefix 'dne.'")
+}
+
+var reasonInt = settings.RegisterIntSetting("reason.int", "desc", 0)
+
+func init() {
+	reasonInt.SetRequireReason()
+}
+
+func TestRequireReason(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	require.True(t, reasonInt.RequiresReason())
+	require.False(t, i1A.RequiresReason())
+
+	u := settings.NewUpdater(sv)
+	require.EqualError(t, u.SetWithReason("reason.int", settings.EncodeInt(1), "i", ""),
+		"setting 'reason.int' requires a reason")
+	require.Equal(t, int64(0), reasonInt.Get(sv))
+
+	require.EqualError(t, u.Set("reason.int", settings.EncodeInt(1), "i"),
+		"setting 'reason.int' requires a reason")
+	require.Equal(t, int64(0), reasonInt.Get(sv))
+
+	require.NoError(t, u.SetWithReason("reason.int", settings.EncodeInt(1), "i", "incident 123"))
+	require.Equal(t, int64(1), reasonInt.Get(sv))
+
+	// Settings that don't require a reason can be changed without one.
+	require.NoError(t, u.SetWithReason("i.1", settings.EncodeInt(1), "i", ""))
+	require.Equal(t, int64(1), i1A.Get(sv))
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(2), "i"))
+	require.Equal(t, int64(2), i1A.Get(sv))
+
+	// Values that were already accepted, e.g. read from system.settings, are
+	// applied without a reason.
+	require.NoError(t, settings.NewRefreshUpdater(sv).Set("reason.int", settings.EncodeInt(2), "i"))
+	require.Equal(t, int64(2), reasonInt.Get(sv))
+}
+
+func TestMetricName(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected string
+	}{
+		{"kv.range.size", "cockroach_setting_kv_range_size"},
+		{"kv.bulk-io.write.max_rate", "cockroach_setting_kv_bulk_io_write_max_rate"},
+		{"server.time_until_store_dead", "cockroach_setting_server_time_until_store_dead"},
+		{"sql.defaults.vectorize_row_count_threshold", "cockroach_setting_sql_defaults_vectorize_row_count_threshold"},
+		{"rocksdb.min_wal_sync_interval", "cockroach_setting_rocksdb_min_wal_sync_interval"},
+		{"Timeseries.Storage.Resolution_10s.TTL", "cockroach_setting_timeseries_storage_resolution_10s_ttl"},
+		{"kv.-1.x--y", "cockroach_setting_kv__1_x__y"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			require.Equal(t, tc.expected, settings.MetricName(tc.key))
+		})
+	}
+}
+
+func TestApplyDefaultsFromFile(t *testing.T) {
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		path := writeFile("defaults.yaml", `
+i.2: 8
+bool.t: false
+str.bar: baz
+d: 1m
+e: baz
+zzz: 2097152
+`)
+		require.NoError(t, settings.ApplyDefaultsFromFile(sv, path))
+		require.Equal(t, int64(8), i2A.Get(sv))
+		require.Equal(t, false, boolTA.Get(sv))
+		require.Equal(t, "baz", strBarA.Get(sv))
+		require.Equal(t, time.Minute, dA.Get(sv))
+		require.Equal(t, int64(3), eA.Get(sv))
+		require.Equal(t, 2*mb, byteSize.Get(sv))
+
+		// Values set through an updater take precedence over the file defaults,
+		// and resetting them reveals the file default rather than the compiled one.
+		u := settings.NewUpdater(sv)
+		require.NoError(t, u.Set("i.2", settings.EncodeInt(9), "i"))
+		require.NoError(t, u.Set("str.bar", "qux", "s"))
+		require.Equal(t, int64(9), i2A.Get(sv))
+		require.Equal(t, "qux", strBarA.Get(sv))
+		settings.NewUpdater(sv).ResetRemaining()
+		require.Equal(t, int64(8), i2A.Get(sv))
+		require.Equal(t, "baz", strBarA.Get(sv))
+		// Settings not mentioned in the file keep their compiled defaults.
+		require.Equal(t, int64(0), i1A.Get(sv))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		path := writeFile("defaults.json", `{"i.2": "8", "f": "1.5"}`)
+		require.NoError(t, settings.ApplyDefaultsFromFile(sv, path))
+		require.Equal(t, int64(8), i2A.Get(sv))
+		require.Equal(t, 1.5, fA.Get(sv))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := []struct {
+			contents string
+			expected string
+		}{
+			{"i.2: 8\ndne: 1\n", "unknown setting 'dne'"},
+			{"i.2: 8\ni.Val: -1\n", "invalid default for setting 'i.Val': int cannot be negative"},
+			{"i.2: 8\nbool.t: maybe\n", "invalid default for setting 'bool.t'"},
+			{"i.2: 8\ne: qux\n", "invalid default for setting 'e': unrecognized value qux"},
+			{"i.2: 8\nstatemachine: a.b\n", "settings of type m do not support defaults"},
+			{"i.2: [8]\n", "parsing settings defaults file"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.expected, func(t *testing.T) {
+				sv := &settings.Values{}
+				sv.Init(settings.TestOpaque)
+				path := writeFile("invalid.yaml", tc.contents)
+				err := settings.ApplyDefaultsFromFile(sv, path)
+				require.True(t, testutils.IsError(err, tc.expected), "expected %q, got %v", tc.expected, err)
+				// A malformed file does not install any of its entries.
+				require.Equal(t, int64(5), i2A.Get(sv))
+			})
+		}
+	})
+}
+
+func TestBatch(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	require.NoError(t, settings.NewBatch().
+		SetInt(i2A, 3).
+		SetBool(boolFA, true).
+		SetFloat(fA, 1.5).
+		SetDuration(dA, time.Minute).
+		SetString(strBarA, "baz").
+		SetInt(reasonInt, 7).
+		WithReason("migration 42").
+		Apply(sv))
+	require.Equal(t, int64(3), i2A.Get(sv))
+	require.True(t, boolFA.Get(sv))
+	require.Equal(t, 1.5, fA.Get(sv))
+	require.Equal(t, time.Minute, dA.Get(sv))
+	require.Equal(t, "baz", strBarA.Get(sv))
+	require.Equal(t, int64(7), reasonInt.Get(sv))
+
+	t.Run("atomic", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		err := settings.NewBatch().
+			SetInt(i2A, 3).
+			SetInt(iVal, -1).
+			SetInt(reasonInt, 7).
+			Apply(sv)
+		require.Error(t, err)
+		require.EqualError(t, err, "setting 'i.Val': int cannot be negative")
+		// Errors from later changes are retained as secondary errors.
+		require.Contains(t, fmt.Sprintf("%+v", err), "setting 'reason.int' requires a reason")
+		// None of the changes were applied, including the valid one.
+		require.Equal(t, int64(5), i2A.Get(sv))
+		require.Equal(t, int64(0), iVal.Get(sv))
+		require.Equal(t, int64(0), reasonInt.Get(sv))
+	})
+}
+
+func TestOnChangeLatency(t *testing.T) {
+	latencyInt, unregister := settings.TestingRegisterInt(t, "latency.int", "desc", 0)
+	defer unregister()
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	type timing struct {
+		key string
+		idx int
+	}
+	var timings []timing
+	sv.SetOnChangeLatencyHook(func(key string, idx int, d time.Duration) {
+		timings = append(timings, timing{key, idx})
+		if idx == 1 {
+			require.GreaterOrEqual(t, int64(d), int64(time.Millisecond))
+		}
+	})
+	latencyInt.SetOnChange(sv, func() {})
+	latencyInt.SetOnChange(sv, func() { time.Sleep(time.Millisecond) })
+
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("latency.int", settings.EncodeInt(1), "i"))
+	require.Equal(t, []timing{{"latency.int", 0}, {"latency.int", 1}}, timings)
+	require.GreaterOrEqual(t, int64(sv.LastOnChangeDuration()), int64(time.Millisecond))
+
+	// Settings without callbacks are not timed.
+	timings = nil
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.Empty(t, timings)
+}
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected int64
+	}{
+		{"0", 0},
+		{"123", 123},
+		{"123 B", 123},
+		// Plain numbers are parsed exactly, even beyond the precision of a
+		// float64.
+		{"9223372036854775807", math.MaxInt64},
+		{"9007199254740993", 1<<53 + 1},
+		{"-9007199254740993", -(1<<53 + 1)},
+		// IEC units are powers of 1024.
+		{"1 KiB", 1 << 10},
+		{"1MiB", 1 << 20},
+		{"1.5 GiB", 3 << 29},
+		{"2 TiB", 2 << 40},
+		{"1 Ki", 1 << 10},
+		// SI units, and the single-letter units, are powers of 1000.
+		{"1 KB", 1000},
+		{"1 MB", 1000 * 1000},
+		{"1.5GB", 1500 * 1000 * 1000},
+		{"1K", 1000},
+		{"1M", 1000 * 1000},
+		// Units are case-insensitive.
+		{"1 mib", 1 << 20},
+		{"1 mb", 1000 * 1000},
+		{"-1 KiB", -1 << 10},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			actual, err := settings.ParseByteSize(tc.s)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+
+	for _, s := range []string{"", "MiB", "1 XB", "1 KiB 2"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := settings.ParseByteSize(s)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("setting", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		u := settings.NewUpdater(sv)
+		require.NoError(t, u.Set("zzz", "2 MiB", "z"))
+		require.Equal(t, 2*mb, byteSize.Get(sv))
+		// Encoded values are plain numbers of bytes.
+		require.NoError(t, u.Set("zzz", settings.EncodeInt(3*mb), "z"))
+		require.Equal(t, 3*mb, byteSize.Get(sv))
+		for _, v := range []int64{math.MaxInt64, 1<<53 + 1} {
+			require.NoError(t, u.Set("zzz", settings.EncodeInt(v), "z"))
+			require.Equal(t, v, byteSize.Get(sv))
+			require.Equal(t, settings.EncodeInt(v), byteSize.Encoded(sv))
+		}
+	})
+}
+
+func TestTestingCaptureChanges(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	changes, restore := settings.TestingCaptureChanges(sv)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.NoError(t, u.Set("str.bar", "baz", "s"))
+	// Setting a value that doesn't change is not recorded.
+	require.NoError(t, u.Set("bool.f", settings.EncodeBool(false), "b"))
+	require.Equal(t, []settings.Change{
+		{Key: "i.2", Encoded: "3"},
+		{Key: "str.bar", Encoded: "baz"},
+	}, *changes)
+
+	restore()
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(4), "i"))
+	require.Len(t, *changes, 2)
+
+	t.Run("concurrent", func(t *testing.T) {
+		changes, restore := settings.TestingCaptureChanges(sv)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = settings.NewUpdater(sv).Set("i.1", settings.EncodeInt(int64(i+1)), "i")
+			}(i)
+		}
+		wg.Wait()
+		restore()
+		for _, c := range *changes {
+			require.Equal(t, "i.1", c.Key)
+		}
+	})
+}
+
+func TestNewRegistry(t *testing.T) {
+	r := settings.NewRegistry()
+	// Keys don't clash with those of the default registry.
+	i := r.RegisterIntSetting("i.2", "desc", 7)
+	s := r.RegisterStringSetting("isolated.str", "desc", "foo")
+	require.Equal(t, 2, r.NumRegisteredSettings())
+	require.Equal(t, []string{"i.2", "isolated.str"}, r.Keys())
+
+	sv := &settings.Values{}
+	r.InitValues(sv, settings.TestOpaque)
+	require.Equal(t, int64(7), i.Get(sv))
+	require.Equal(t, "foo", s.Get(sv))
+
+	u := r.MakeUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(9), "i"))
+	require.Equal(t, int64(9), i.Get(sv))
+	// Settings of the default registry are unknown.
+	require.EqualError(t, u.Set("i.1", settings.EncodeInt(1), "i"), "unknown setting 'i.1'")
+	u.ResetRemaining()
+	require.Equal(t, int64(9), i.Get(sv))
+	require.Equal(t, "foo", s.Get(sv))
+
+	setting, ok := r.Lookup("i.2", settings.LookupForLocalAccess)
+	require.True(t, ok)
+	require.Equal(t, i, setting)
+	_, ok = r.Lookup("i.1", settings.LookupForLocalAccess)
+	require.False(t, ok)
+
+	// The default registry is unaffected.
+	setting, ok = settings.Lookup("i.2", settings.LookupForLocalAccess)
+	require.True(t, ok)
+	require.Equal(t, i2A, setting)
+	_, ok = settings.Lookup("isolated.str", settings.LookupForLocalAccess)
+	require.False(t, ok)
+}
+
+func TestSetMulti(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	require.NoError(t, settings.SetMulti(sv, map[string]settings.EncodedValue{
+		"i.2":        {Raw: settings.EncodeInt(3), Type: "i"},
+		"bool.f":     {Raw: settings.EncodeBool(true), Type: "b"},
+		"str.bar":    {Raw: "baz", Type: "s"},
+		"reason.int": {Raw: settings.EncodeInt(7), Type: "i"},
+	}, "migration 42"))
+	require.Equal(t, int64(3), i2A.Get(sv))
+	require.True(t, boolFA.Get(sv))
+	require.Equal(t, "baz", strBarA.Get(sv))
+	require.Equal(t, int64(7), reasonInt.Get(sv))
+
+	t.Run("atomic", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		err := settings.SetMulti(sv, map[string]settings.EncodedValue{
+			"i.2":        {Raw: settings.EncodeInt(3), Type: "i"},
+			"i.Val":      {Raw: settings.EncodeInt(-1), Type: "i"},
+			"reason.int": {Raw: settings.EncodeInt(7), Type: "i"},
+			"dne":        {Raw: "1", Type: "i"},
+		}, "" /* reason */)
+		require.EqualError(t, err, "failed to set dne, i.Val, reason.int: unknown setting 'dne'")
+		// None of the changes were applied, including the valid one.
+		require.Equal(t, int64(5), i2A.Get(sv))
+		require.Equal(t, int64(0), iVal.Get(sv))
+		require.Equal(t, int64(0), reasonInt.Get(sv))
+	})
+
+	t.Run("frozen", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		defer settings.FreezeKeys(sv, []string{"i.2"}, "migration")()
+		err := settings.SetMulti(sv, map[string]settings.EncodedValue{
+			"i.1": {Raw: settings.EncodeInt(3), Type: "i"},
+			"i.2": {Raw: settings.EncodeInt(4), Type: "i"},
+		}, "" /* reason */)
+		require.True(t, errors.Is(err, settings.ErrFrozen), "%+v", err)
+		require.Equal(t, int64(0), i1A.Get(sv))
+		require.Equal(t, int64(5), i2A.Get(sv))
+
+		err = settings.NewBatch().SetInt(i1A, 7).SetInt(i2A, 8).Apply(sv)
+		require.True(t, errors.Is(err, settings.ErrFrozen), "%+v", err)
+		require.Equal(t, int64(0), i1A.Get(sv))
+		require.Equal(t, int64(5), i2A.Get(sv))
+
+		err = settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+			if err := txn.Set("i.1", settings.EncodeInt(9)); err != nil {
+				return err
+			}
+			return txn.Set("i.2", settings.EncodeInt(9))
+		})
+		require.True(t, errors.Is(err, settings.ErrFrozen), "%+v", err)
+		require.Equal(t, int64(0), i1A.Get(sv))
+		require.Equal(t, int64(5), i2A.Get(sv))
+	})
+
+	t.Run("concurrent freeze", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			sv := &settings.Values{}
+			sv.Init(settings.TestOpaque)
+			frozen := make(chan func())
+			go func() {
+				frozen <- settings.FreezeKeys(sv, []string{"i.2"}, "migration")
+			}()
+			err := settings.SetMulti(sv, map[string]struct{ Raw, Type string }{
+				"i.1": {Raw: settings.EncodeInt(3), Type: "i"},
+				"i.2": {Raw: settings.EncodeInt(4), Type: "i"},
+			}, "" /* reason */)
+			(<-frozen)()
+			// The freeze either happened before the values were validated, in
+			// which case none is applied, or after they were all applied.
+			if err != nil {
+				require.True(t, errors.Is(err, settings.ErrFrozen), "%+v", err)
+				require.Equal(t, int64(0), i1A.Get(sv))
+				require.Equal(t, int64(5), i2A.Get(sv))
+			} else {
+				require.Equal(t, int64(3), i1A.Get(sv))
+				require.Equal(t, int64(4), i2A.Get(sv))
+			}
+		}
+	})
+}
+
+func TestTestingSetDefault(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+
+	restoreInt := settings.TestingSetDefault(sv, i2A, "8")
+	restoreStr := settings.TestingSetDefault(sv, strBarA, "baz")
+	// The current values are unchanged.
+	require.Equal(t, int64(3), i2A.Get(sv))
+	require.Equal(t, "bar", strBarA.Get(sv))
+
+	// Resetting lands on the overridden defaults.
+	settings.NewUpdater(sv).ResetRemaining()
+	require.Equal(t, int64(8), i2A.Get(sv))
+	require.Equal(t, "baz", strBarA.Get(sv))
+
+	restoreInt()
+	restoreStr()
+	settings.NewUpdater(sv).ResetRemaining()
+	require.Equal(t, int64(5), i2A.Get(sv))
+	require.Equal(t, "bar", strBarA.Get(sv))
+
+	t.Run("nested", func(t *testing.T) {
+		sv := &settings.Values{}
+		sv.Init(settings.TestOpaque)
+		i2A.Override(sv, 6)
+		restore := settings.TestingSetDefault(sv, i2A, "8")
+		settings.NewUpdater(sv).ResetRemaining()
+		require.Equal(t, int64(8), i2A.Get(sv))
+		// Restoring reinstates the previous override rather than the compiled
+		// default.
+		restore()
+		settings.NewUpdater(sv).ResetRemaining()
+		require.Equal(t, int64(6), i2A.Get(sv))
+	})
+}
+
+var _ = settings.RegisterIntSetting(
+	"lint.documented", "a setting with a description that passes the lints.", 0)
+var _ = settings.RegisterIntSetting("lint.undocumented", "desc", 0)
+
+func TestLint(t *testing.T) {
+	errs := make(map[string]bool)
+	for _, err := range settings.Lint() {
+		errs[err.Error()] = true
+	}
+	require.True(t, errs[`setting 'lint.undocumented' has a description that is too short: "desc"`])
+	require.True(t, errs[`setting 'lint.undocumented' has a description that does not end with punctuation: "desc"`])
+	for err := range errs {
+		require.NotContains(t, err, "'lint.documented'")
+	}
+}
+
+func TestValueAndReason(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+
+	value, reason, ok := settings.ValueAndReason(sv, "i.2")
+	require.True(t, ok)
+	require.Equal(t, "5", value)
+	require.Equal(t, "", reason)
+
+	require.NoError(t, u.SetWithReason("i.2", settings.EncodeInt(3), "i", "incident 123"))
+	value, reason, ok = settings.ValueAndReason(sv, "i.2")
+	require.True(t, ok)
+	require.Equal(t, "3", value)
+	require.Equal(t, "incident 123", reason)
+
+	// Setting the same value again without a reason, as happens when settings
+	// are refreshed, keeps the reason.
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	_, reason, _ = settings.ValueAndReason(sv, "i.2")
+	require.Equal(t, "incident 123", reason)
+
+	// Changing the value without a reason forgets the old reason, including
+	// when the value is later changed back.
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(4), "i"))
+	value, reason, _ = settings.ValueAndReason(sv, "i.2")
+	require.Equal(t, "4", value)
+	require.Equal(t, "", reason)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	_, reason, _ = settings.ValueAndReason(sv, "i.2")
+	require.Equal(t, "", reason)
+
+	_, _, ok = settings.ValueAndReason(sv, "dne")
+	require.False(t, ok)
+}
+
+func TestUpdaterWithContext(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	ctx := settings.ContextWithReason(context.Background(), "migration 42")
+	require.Equal(t, "migration 42", settings.ReasonFromContext(ctx))
+	require.Equal(t, "", settings.ReasonFromContext(context.Background()))
+
+	u := settings.NewUpdaterWithContext(ctx, sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.NoError(t, u.SetWithReason("bool.t", settings.EncodeBool(false), "b", ""))
+	require.NoError(t, u.SetWithReason("str.bar", "baz", "s", "explicit"))
+	// Settings requiring a reason are satisfied by the one from the context.
+	require.NoError(t, u.Set("reason.int", settings.EncodeInt(7), "i"))
+
+	for key, expected := range map[string]string{
+		"i.2":        "migration 42",
+		"bool.t":     "migration 42",
+		"str.bar":    "explicit",
+		"reason.int": "migration 42",
+	} {
+		_, reason, ok := settings.ValueAndReason(sv, key)
+		require.True(t, ok)
+		require.Equal(t, expected, reason, key)
+	}
+
+	// Without a reason in the context, nothing changes.
+	u = settings.NewUpdaterWithContext(context.Background(), sv)
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(3), "i"))
+	_, reason, _ := settings.ValueAndReason(sv, "i.1")
+	require.Equal(t, "", reason)
+	require.EqualError(t, u.SetWithReason("reason.int", settings.EncodeInt(8), "i", ""),
+		"setting 'reason.int' requires a reason")
+}
+
+func TestWatch(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+
+	intCh, stopInt := i2A.Watch(sv)
+	boolCh, stopBool := boolTA.Watch(sv)
+	floatCh, stopFloat := fA.Watch(sv)
+	durationCh, stopDuration := dA.Watch(sv)
+	enumCh, stopEnum := eA.Watch(sv)
+	defer stopBool()
+	defer stopFloat()
+	defer stopDuration()
+	defer stopEnum()
+
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.NoError(t, u.Set("bool.t", settings.EncodeBool(false), "b"))
+	require.NoError(t, u.Set("f", settings.EncodeFloat(3.5), "f"))
+	require.NoError(t, u.Set("d", settings.EncodeDuration(2*time.Minute), "d"))
+	require.NoError(t, u.Set("e", settings.EncodeInt(2), "e"))
+	require.Equal(t, int64(3), <-intCh)
+	require.Equal(t, false, <-boolCh)
+	require.Equal(t, 3.5, <-floatCh)
+	require.Equal(t, 2*time.Minute, <-durationCh)
+	require.Equal(t, int64(2), <-enumCh)
+
+	// A slow consumer only sees the latest value.
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(4), "i"))
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(6), "i"))
+	require.Equal(t, int64(6), <-intCh)
+	select {
+	case v := <-intCh:
+		t.Fatalf("unexpected value %d", v)
+	default:
+	}
+
+	// Stopping the watch closes the channel and no more values are delivered.
+	stopInt()
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(7), "i"))
+	_, ok := <-intCh
+	require.False(t, ok)
+	stopInt()
+}
+
+func TestFreezeKeys(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+
+	unfreezeA := settings.FreezeKeys(sv, []string{"i.1", "i.2"}, "migration a")
+	unfreezeB := settings.FreezeKeys(sv, []string{"i.2"}, "migration b")
+
+	// Only the frozen keys are rejected.
+	err := u.Set("i.1", settings.EncodeInt(1), "i")
+	require.True(t, errors.Is(err, settings.ErrFrozen))
+	require.EqualError(t, err, "cannot change setting 'i.1' (migration a): setting is frozen")
+	err = u.Set("i.2", settings.EncodeInt(1), "i")
+	require.True(t, errors.Is(err, settings.ErrFrozen))
+	require.EqualError(t, err, "cannot change setting 'i.2' (migration b): setting is frozen")
+	require.NoError(t, u.Set("bool.t", settings.EncodeBool(false), "b"))
+	require.Equal(t, int64(0), i1A.Get(sv))
+	require.Equal(t, int64(5), i2A.Get(sv))
+	require.False(t, boolTA.Get(sv))
+
+	// A key stays frozen until all the freezes including it are released.
+	unfreezeA()
+	unfreezeA()
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(1), "i"))
+	err = u.Set("i.2", settings.EncodeInt(1), "i")
+	require.True(t, errors.Is(err, settings.ErrFrozen))
+	unfreezeB()
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(1), "i"))
+	require.Equal(t, int64(1), i1A.Get(sv))
+	require.Equal(t, int64(1), i2A.Get(sv))
+}
+
+func TestWithTemporaryValue(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	require.NoError(t, settings.WithTemporaryValue(sv, i2A, settings.EncodeInt(3), func() {
+		require.Equal(t, int64(3), i2A.Get(sv))
+	}))
+	require.Equal(t, int64(5), i2A.Get(sv))
+
+	// The value is restored even if the function panics.
+	require.Panics(t, func() {
+		_ = settings.WithTemporaryValue(sv, strBarA, "baz", func() {
+			require.Equal(t, "baz", strBarA.Get(sv))
+			panic("boom")
+		})
+	})
+	require.Equal(t, "bar", strBarA.Get(sv))
+
+	// Invalid values are rejected without running the function.
+	err := settings.WithTemporaryValue(sv, iVal, settings.EncodeInt(-1), func() {
+		t.Fatal("unexpected call")
+	})
+	require.EqualError(t, err, "int cannot be negative")
+	require.Equal(t, int64(0), iVal.Get(sv))
+}
+
+func TestCoalesce(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+
+	c := settings.Coalesce(i1A, i2A)
+	require.Equal(t, "i", c.Typ())
+
+	// The primary is at its default, so the fallback wins.
+	require.Equal(t, settings.WritableSetting(i2A), c.Effective(sv))
+	require.Equal(t, "5", c.Get(sv))
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(7), "i"))
+	require.Equal(t, "7", c.Get(sv))
+
+	// Once the primary is changed, it wins.
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(3), "i"))
+	require.Equal(t, settings.WritableSetting(i1A), c.Effective(sv))
+	require.Equal(t, "3", c.Get(sv))
+	require.Equal(t, "3", c.String(sv))
+
+	require.Panics(t, func() { settings.Coalesce(i1A, boolTA) })
+}
+
+func TestBulkGet(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.NoError(t, u.Set("e", settings.EncodeInt(2), "e"))
+
+	values := settings.BulkGet(sv, []string{"i.2", "bool.t", "f", "d", "str.bar", "zzz", "e", "dne"})
+	require.Equal(t, map[string]interface{}{
+		"i.2":     i2A.Get(sv),
+		"bool.t":  boolTA.Get(sv),
+		"f":       fA.Get(sv),
+		"d":       dA.Get(sv),
+		"str.bar": strBarA.Get(sv),
+		"zzz":     byteSize.Get(sv),
+		"e":       eA.Get(sv),
+	}, values)
+	require.Equal(t, int64(3), values["i.2"])
+	require.Equal(t, int64(2), values["e"])
+}
+
+func TestCollectNumericValues(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.NoError(t, u.Set("zzz", settings.EncodeInt(1<<20), "z"))
+	require.NoError(t, u.Set("d", settings.EncodeDuration(time.Minute), "d"))
+
+	values := settings.CollectNumericValues(sv)
+	require.Equal(t, int64(3), values["i.2"])
+	require.Equal(t, int64(1<<20), values["zzz"])
+	require.Equal(t, int64(time.Minute), values["d"])
+	require.Equal(t, int64(duA.Get(sv)), values["d_with_explicit_unit"])
+	for _, k := range []string{"bool.t", "f", "str.bar", "e", "statemachine", "reset.retired"} {
+		_, ok := values[k]
+		require.False(t, ok, k)
+	}
+}
+
+// BenchmarkBulkGet compares BulkGet to reading each setting with Lookup and
+// its Get method.
+func BenchmarkBulkGet(b *testing.B) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	keys := settings.Keys()
+
+	b.Run("bulk", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = settings.BulkGet(sv, keys)
+		}
+	})
+	b.Run("individual", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			res := make(map[string]interface{}, len(keys))
+			for _, k := range keys {
+				s, _ := settings.Lookup(k, settings.LookupForLocalAccess)
+				switch s := s.(type) {
+				case *settings.IntSetting:
+					res[k] = s.Get(sv)
+				case *settings.ByteSizeSetting:
+					res[k] = s.Get(sv)
+				case *settings.EnumSetting:
+					res[k] = s.Get(sv)
+				case *settings.BoolSetting:
+					res[k] = s.Get(sv)
+				case *settings.FloatSetting:
+					res[k] = s.Get(sv)
+				case *settings.DurationSetting:
+					res[k] = s.Get(sv)
+				case *settings.DurationSettingWithExplicitUnit:
+					res[k] = s.Get(sv)
+				case *settings.StringSetting:
+					res[k] = s.Get(sv)
+				case *settings.StateMachineSetting:
+					if s.GetInternal(sv) != nil {
+						res[k] = s.Get(sv)
+					}
+				}
+			}
+		}
+	})
+}
+
+func TestInvariants(t *testing.T) {
+	r := settings.NewRegistry()
+	minBatch := r.RegisterIntSetting("batch.min", "desc", 1)
+	maxBatch := r.RegisterIntSetting("batch.max", "desc", 10)
+	r.RegisterInvariant("batch.min <= batch.max", func(sv *settings.Values) error {
+		if min, max := minBatch.Get(sv), maxBatch.Get(sv); min > max {
+			return errors.Errorf("%d > %d", min, max)
+		}
+		return nil
+	})
+	require.Panics(t, func() {
+		r.RegisterInvariant("batch.min <= batch.max", func(*settings.Values) error { return nil })
+	})
+
+	sv := &settings.Values{}
+	r.InitValues(sv, settings.TestOpaque)
+	require.Empty(t, settings.ValidateInvariants(sv))
+
+	// Each setting is valid on its own, but not together.
+	u := r.MakeUpdater(sv)
+	require.NoError(t, u.Set("batch.min", settings.EncodeInt(20), "i"))
+	errs := settings.ValidateInvariants(sv)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "invariant batch.min <= batch.max violated: 20 > 10")
+
+	require.NoError(t, u.Set("batch.max", settings.EncodeInt(20), "i"))
+	require.Empty(t, r.ValidateInvariants(sv))
+}
+
+func TestEnvVarName(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected string
+	}{
+		{"kv.rangefeed.enabled", "COCKROACH_KV_RANGEFEED_ENABLED"},
+		{"kv.bulk-io.write.max_rate", "COCKROACH_KV_BULK_IO_WRITE_MAX_RATE"},
+		{"Timeseries.Storage.Resolution_10s.TTL", "COCKROACH_TIMESERIES_STORAGE_RESOLUTION_10S_TTL"},
+		{"a b/c:d=é", "COCKROACH_A_B_C_D__"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			require.Equal(t, tc.expected, settings.EnvVarName(tc.key))
+		})
+	}
+}
+
+func TestExportEnv(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	require.Empty(t, settings.ExportEnv(sv))
+
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(3), "i"))
+	require.NoError(t, u.Set("bool.t", settings.EncodeBool(false), "b"))
+	require.NoError(t, u.Set("str.bar", "baz qux", "s"))
+	require.NoError(t, u.Set("d", settings.EncodeDuration(2*time.Minute), "d"))
+	require.NoError(t, u.Set("sekretz", settings.EncodeBool(true), "b"))
+	env := settings.ExportEnv(sv)
+	require.Equal(t, []string{
+		"COCKROACH_BOOL_T=false",
+		"COCKROACH_D=2m0s",
+		"COCKROACH_I_2=3",
+		"COCKROACH_SEKRETZ=<redacted>",
+		"COCKROACH_STR_BAR=<redacted>",
+	}, env)
+
+	// Applying the exported environment reproduces the values of all the
+	// settings but the masked ones; string settings are non-reportable by
+	// default.
+	sv2 := &settings.Values{}
+	sv2.Init(settings.TestOpaque)
+	require.NoError(t, settings.ApplyDefaultsFromEnv(sv2, append(env, "HOME=/root", "COCKROACH_UNKNOWN=1")))
+	require.Equal(t, int64(3), i2A.Get(sv2))
+	require.False(t, boolTA.Get(sv2))
+	require.Equal(t, "bar", strBarA.Get(sv2))
+	require.Equal(t, 2*time.Minute, dA.Get(sv2))
+	sekretz, _ := settings.Lookup("sekretz", settings.LookupForLocalAccess)
+	require.Equal(t, "false", sekretz.String(sv2))
+
+	require.Error(t, settings.ApplyDefaultsFromEnv(sv2, []string{"COCKROACH_I_VAL=-1"}))
+}
+
+var cacheSize = settings.RegisterByteSizeSettingWithMinimum("cache.size", "desc", 64<<20, 16<<20)
+
+func TestByteSizeSettingWithMinimum(t *testing.T) {
+	defer settings.TestingSaveRegistry()()
+	require.PanicsWithError(t,
+		"invalid default: cannot set cache.too_small to 1.0 MiB, which is below the minimum of 16 MiB",
+		func() { settings.RegisterByteSizeSettingWithMinimum("cache.too_small", "desc", 1<<20, 16<<20) })
+
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	require.Equal(t, int64(64<<20), cacheSize.Get(sv))
+	u := settings.NewUpdater(sv)
+
+	// The minimum itself is allowed.
+	require.NoError(t, u.Set("cache.size", settings.EncodeInt(16<<20), "z"))
+	require.Equal(t, int64(16<<20), cacheSize.Get(sv))
+
+	require.EqualError(t, u.Set("cache.size", settings.EncodeInt(16<<20-1), "z"),
+		"cannot set cache.size to 16 MiB, which is below the minimum of 16 MiB")
+	require.EqualError(t, u.Set("cache.size", "1MiB", "z"),
+		"cannot set cache.size to 1.0 MiB, which is below the minimum of 16 MiB")
+	require.EqualError(t, u.Set("cache.size", settings.EncodeInt(-1), "z"),
+		"cannot set cache.size to a negative value: -1")
+	require.Equal(t, int64(16<<20), cacheSize.Get(sv))
+}
+
+var bufferSize = settings.RegisterByteSizeSettingWithUnit("buffer.size", "desc", 1<<30, "MiB")
+
+func TestByteSizeSettingWithUnit(t *testing.T) {
+	require.PanicsWithError(t, `invalid unit "MiBs" for setting buffer.bad`,
+		func() { settings.RegisterByteSizeSettingWithUnit("buffer.bad", "desc", 1, "MiBs") })
+
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	require.Equal(t, "MiB", bufferSize.PreferredUnit())
+	require.Equal(t, "", byteSize.PreferredUnit())
+	require.Equal(t, "", i1A.PreferredUnit())
+	// The unit is available through the Setting interface, e.g. for settings
+	// found with Lookup.
+	s, ok := settings.Lookup("buffer.size", settings.LookupForLocalAccess)
+	require.True(t, ok)
+	require.Equal(t, "MiB", s.PreferredUnit())
+
+	// The value is displayed in MiB even though it is a whole number of GiB.
+	require.Equal(t, "1024 MiB", bufferSize.String(sv))
+
+	// Any unit is accepted when setting the value.
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("buffer.size", "1.5GiB", "z"))
+	require.Equal(t, int64(1536<<20), bufferSize.Get(sv))
+	require.Equal(t, "1536 MiB", bufferSize.String(sv))
+	require.NoError(t, u.Set("buffer.size", "512KiB", "z"))
+	require.Equal(t, "0.5 MiB", bufferSize.String(sv))
+}
+
+func TestExportChangeLog(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.Empty(t, settings.ExportChangeLog(sv))
+
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(1), "i"))
+	// Sets that don't change the value, or fail, are not recorded.
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(1), "i"))
+	require.Error(t, u.Set("i.Val", settings.EncodeInt(-1), "i"))
+	require.NoError(t, u.SetWithReason("i.1", settings.EncodeInt(2), "i", "incident 123"))
+	require.NoError(t, u.Set("bool.t", settings.EncodeBool(false), "b"))
+	require.NoError(t, u.Set("sekretz", settings.EncodeBool(true), "b"))
+	require.NoError(t, settings.NewBatch().SetInt(i2A, 3).WithReason("migration").Apply(sv))
+	require.NoError(t, settings.SetMulti(sv, map[string]settings.EncodedValue{
+		"i.2": {Raw: settings.EncodeInt(4), Type: "i"},
+	}, "" /* reason */))
+	require.NoError(t, settings.NewRefreshUpdater(sv).Set("i.2", settings.EncodeInt(5), "i"))
+	require.NoError(t, settings.ApplyChanges(sv, []settings.Change{{Key: "i.2", Encoded: "6"}}))
+	require.NoError(t, settings.RunInTxn(sv, "txn", func(txn *settings.Txn) error {
+		return txn.Set("i.2", settings.EncodeInt(7))
+	}))
+
+	type change struct {
+		key, old, new, reason string
+		provenance            settings.Provenance
+	}
+	var changes []change
+	records := settings.ExportChangeLog(sv)
+	for i, r := range records {
+		require.False(t, r.Timestamp.IsZero())
+		if i > 0 {
+			require.False(t, r.Timestamp.Before(records[i-1].Timestamp))
+		}
+		changes = append(changes, change{r.Key, r.Old, r.New, r.Reason, r.Provenance})
+	}
+	require.Equal(t, []change{
+		{"i.1", "0", "1", "", settings.ProvenanceUpdater},
+		{"i.1", "1", "2", "incident 123", settings.ProvenanceUpdater},
+		{"bool.t", "true", "false", "", settings.ProvenanceUpdater},
+		{"sekretz", "<redacted>", "<redacted>", "", settings.ProvenanceUpdater},
+		{"i.2", "5", "3", "migration", settings.ProvenanceBatch},
+		{"i.2", "3", "4", "", settings.ProvenanceSetMulti},
+		{"i.2", "4", "5", "", settings.ProvenanceRefresh},
+		{"i.2", "5", "6", "", settings.ProvenanceSync},
+		{"i.2", "6", "7", "txn", settings.ProvenanceTxn},
+	}, changes)
+
+	// Draining returns the same records and empties the log.
+	require.Equal(t, records, settings.DrainChangeLog(sv))
+	require.Empty(t, settings.ExportChangeLog(sv))
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(3), "i"))
+	require.Len(t, settings.DrainChangeLog(sv), 1)
+
+	// Only the most recent changes are kept.
+	for i := 1; i <= 1005; i++ {
+		require.NoError(t, u.Set("i.1", settings.EncodeInt(int64(3+i)), "i"))
+	}
+	records = settings.ExportChangeLog(sv)
+	require.Len(t, records, 1000)
+	require.Equal(t, "8", records[0].Old)
+	require.Equal(t, "1008", records[len(records)-1].New)
+}
+
+// BenchmarkGet measures reading primitive settings, which load their value
+// from the slot assigned to them at registration without any map lookup.
+func BenchmarkGet(b *testing.B) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	b.Run("int", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = i1A.Get(sv)
+		}
+	})
+	b.Run("bool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = boolTA.Get(sv)
+		}
+	})
+	b.Run("bool/fast", func(b *testing.B) {
+		b.ReportAllocs()
+		p := boolTA.FastValuePtr(sv)
+		for i := 0; i < b.N; i++ {
+			_ = atomic.LoadInt64(p) != 0
+		}
+	})
+	b.Run("float", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = fA.Get(sv)
+		}
+	})
+	b.Run("duration", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = dA.Get(sv)
+		}
+	})
+	b.Run("duration/explicit-unit", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = duA.Get(sv)
+		}
+	})
+	b.Run("bytesize", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = byteSize.Get(sv)
+		}
+	})
+	b.Run("enum", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = eA.Get(sv)
+		}
+	})
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = strBarA.Get(sv)
+		}
+	})
+	b.Run("lookup", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s, _ := settings.Lookup("i.1", settings.LookupForLocalAccess)
+			_ = s.(*settings.IntSetting).Get(sv)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = i1A.Get(sv)
+			}
+		})
+	})
+}
+
+// BenchmarkUpdate measures refreshing the settings as done when the
+// system.settings table changes: a batch of settings is set through a single
+// Updater, after which the remaining ones are reset to their defaults.
+func BenchmarkUpdate(b *testing.B) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	type update struct{ key, raw, typ string }
+	batches := [2][]update{
+		{
+			{"i.1", settings.EncodeInt(1), "i"},
+			{"bool.f", settings.EncodeBool(true), "b"},
+			{"f", settings.EncodeFloat(1.5), "f"},
+			{"d", settings.EncodeDuration(time.Minute), "d"},
+			{"zzz", settings.EncodeInt(1 << 20), "z"},
+			{"e", settings.EncodeInt(2), "e"},
+			{"str.bar", "foo", "s"},
+		},
+		{
+			{"i.1", settings.EncodeInt(2), "i"},
+			{"bool.f", settings.EncodeBool(false), "b"},
+			{"f", settings.EncodeFloat(2.5), "f"},
+			{"d", settings.EncodeDuration(time.Hour), "d"},
+			{"zzz", settings.EncodeInt(2 << 20), "z"},
+			{"e", settings.EncodeInt(3), "e"},
+			{"str.bar", "bar", "s"},
+		},
+	}
+
+	b.Run("set", func(b *testing.B) {
+		b.ReportAllocs()
+		u := settings.NewUpdater(sv)
+		for i := 0; i < b.N; i++ {
+			up := batches[0][i%len(batches[0])]
+			if err := u.Set(up.key, up.raw, up.typ); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			u := settings.NewUpdater(sv)
+			// Alternate between the batches so that every Set changes a value.
+			for _, up := range batches[i%2] {
+				if err := u.Set(up.key, up.raw, up.typ); err != nil {
+					b.Fatal(err)
+				}
+			}
+			u.ResetRemaining()
+		}
+	})
+}
+
+func TestMemoize(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+
+	var calls int32
+	get := settings.Memoize(sv, i2A, func() interface{} {
+		atomic.AddInt32(&calls, 1)
+		return fmt.Sprintf("value %d", i2A.Get(sv))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				require.Equal(t, "value 5", get())
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Changes of other settings, or sets to the same value, don't invalidate
+	// the result.
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(1), "i"))
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(5), "i"))
+	require.Equal(t, "value 5", get())
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(6), "i"))
+	for i := 0; i < 10; i++ {
+		require.Equal(t, "value 6", get())
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOnReadyOnce(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	var before, after int
+	settings.OnReadyOnce(sv, func() { before++ })
+	require.False(t, sv.IsReady())
+	require.Equal(t, 0, before)
+
+	sv.MarkReady()
+	require.True(t, sv.IsReady())
+	require.Equal(t, 1, before)
+
+	settings.OnReadyOnce(sv, func() { after++ })
+	require.Equal(t, 1, after)
+
+	// Marking the values ready again doesn't run any of the functions again.
+	sv.MarkReady()
+	require.Equal(t, 1, before)
+	require.Equal(t, 1, after)
+}
+
+func TestParseFlagEnum(t *testing.T) {
+	labels := map[int64]string{1: "a", 2: "B", 4: "c"}
+	testCases := []struct {
+		input       string
+		expected    int64
+		expectedErr string
+	}{
+		{"", 0, ""},
+		{"  ", 0, ""},
+		{"a", 1, ""},
+		{"a,b,c", 7, ""},
+		{"a, b ,c", 7, ""},
+		{" C,A ", 5, ""},
+		{"b,b", 2, ""},
+		{"a, b, a", 3, ""},
+		{"d", 0, `unknown flag "d" in "d", valid flags are [a = 1, b = 2, c = 4]`},
+		{"a, x", 0, `unknown flag "x" in "a, x", valid flags are [a = 1, b = 2, c = 4]`},
+		{"a,,b", 0, `unknown flag "" in "a,,b", valid flags are [a = 1, b = 2, c = 4]`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			flags, err := settings.ParseFlagEnum(tc.input, labels)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, flags)
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		input       string
+		expected    time.Duration
+		expectedErr string
+	}{
+		{"1h30m", 90 * time.Minute, ""},
+		{"-1.5s", -1500 * time.Millisecond, ""},
+		{"0s", 0, ""},
+		{"PT1H30M", 90 * time.Minute, ""},
+		{"pt1h30m", 90 * time.Minute, ""},
+		{"P1DT12H", 36 * time.Hour, ""},
+		{"P2W", 14 * 24 * time.Hour, ""},
+		{"PT0.5S", 500 * time.Millisecond, ""},
+		{"-PT10S", -10 * time.Second, ""},
+		{"P", 0, `invalid ISO 8601 duration "P"`},
+		{"PT", 0, `invalid ISO 8601 duration "PT"`},
+		{"P1M", 0, `invalid ISO 8601 duration "P1M"`},
+		{"P1Y", 0, `invalid ISO 8601 duration "P1Y"`},
+		{"PT1D", 0, `invalid ISO 8601 duration "PT1D"`},
+		{"P1H", 0, `invalid ISO 8601 duration "P1H"`},
+		{"PT1", 0, `invalid ISO 8601 duration "PT1"`},
+		{"PTH", 0, `invalid ISO 8601 duration "PTH"`},
+		{"PT1S1H", 0, `invalid ISO 8601 duration "PT1S1H"`},
+		{"P1D2W", 0, `invalid ISO 8601 duration "P1D2W"`},
+		{"PT1H1H", 0, `invalid ISO 8601 duration "PT1H1H"`},
+		{"P1DT1M1M", 0, `invalid ISO 8601 duration "P1DT1M1M"`},
+		{"PT1.5H30M", 0, `invalid ISO 8601 duration "PT1.5H30M"`},
+		{"P1.5DT1H", 0, `invalid ISO 8601 duration "P1.5DT1H"`},
+		{"PT1H1.5M", 90*time.Second + time.Hour, ""},
+		{"P1W2DT3H4M5S", 9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, ""},
+		{"P1000000W", 0, `ISO 8601 duration "P1000000W" is out of range`},
+		{"1 hour", 0, `time: unknown unit " hour" in duration "1 hour"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			d, err := settings.ParseDuration(tc.input)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, d)
+
+			// The formatted duration parses back to the same duration.
+			formatted := settings.FormatDuration(d)
+			require.Equal(t, d.String(), formatted)
+			roundTripped, err := settings.ParseDuration(formatted)
+			require.NoError(t, err)
+			require.Equal(t, d, roundTripped)
+		})
+	}
+
+	// Duration settings accept both formats.
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("d", "PT1M30S", "d"))
+	require.Equal(t, 90*time.Second, dA.Get(sv))
+	require.Equal(t, "1m30s", dA.String(sv))
+}
+
+func TestEnumLabel(t *testing.T) {
+	for v, expected := range map[int64]string{1: "foo", 2: "bar", 3: "baz"} {
+		label, ok := settings.EnumLabel(eA, v)
+		require.True(t, ok)
+		require.Equal(t, expected, label)
+	}
+	for _, v := range []int64{0, 4, -1} {
+		label, ok := settings.EnumLabel(eA, v)
+		require.False(t, ok)
+		require.Empty(t, label)
+	}
+}
+
+func TestRegistrySize(t *testing.T) {
+	// Keys omits retired settings.
+	require.GreaterOrEqual(t, settings.NumRegisteredSettings(), len(settings.Keys()))
+	require.Greater(t, settings.ApproxRegistryBytes(), 0)
+
+	r := settings.NewRegistry()
+	empty := r.ApproxBytes()
+	require.Equal(t, 0, r.NumRegisteredSettings())
+
+	r.RegisterIntSetting("size.a", "desc", 1)
+	r.RegisterBoolSetting("size.b", "desc", true)
+	r.RegisterStringSetting("size.c", "desc", "c")
+	require.Equal(t, 3, r.NumRegisteredSettings())
+	three := r.ApproxBytes()
+	require.Greater(t, three, empty)
+
+	// Longer descriptions take more space.
+	r.RegisterIntSetting("size.d", "a much longer description of the setting", 1)
+	require.Equal(t, 4, r.NumRegisteredSettings())
+	require.Greater(t, r.ApproxBytes()-three, len("a much longer description of the setting"))
+}
+
+func TestRegisterAliasWithTransform(t *testing.T) {
+	r := settings.NewRegistry()
+	timeoutMillis := r.RegisterIntSetting("timeout.millis", "desc", 1000)
+	r.RegisterAliasWithTransform("timeout.seconds", "timeout.millis", func(s string) (string, error) {
+		secs, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		return settings.EncodeInt(secs * 1000), nil
+	})
+
+	sv := &settings.Values{}
+	r.InitValues(sv, settings.TestOpaque)
+	u := r.MakeUpdater(sv)
+
+	require.NoError(t, u.Set("timeout.seconds", settings.EncodeInt(3), "i"))
+	require.Equal(t, int64(3000), timeoutMillis.Get(sv))
+	require.NoError(t, u.SetWithReason("timeout.seconds", settings.EncodeInt(5), "i", "slow disks"))
+	require.Equal(t, int64(5000), timeoutMillis.Get(sv))
+	require.NoError(t, u.Set("timeout.millis", settings.EncodeInt(10), "i"))
+	require.Equal(t, int64(10), timeoutMillis.Get(sv))
+
+	err := u.Set("timeout.seconds", "abc", "i")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "setting 'timeout.seconds' (alias of 'timeout.millis')")
+	require.Equal(t, int64(10), timeoutMillis.Get(sv))
+
+	// The new setting counts as updated, so it is not reset.
+	u = r.MakeUpdater(sv)
+	require.NoError(t, u.Set("timeout.seconds", settings.EncodeInt(2), "i"))
+	u.ResetRemaining()
+	require.Equal(t, int64(2000), timeoutMillis.Get(sv))
+
+	identity := func(s string) (string, error) { return s, nil }
+	require.Panics(t, func() { r.RegisterAliasWithTransform("timeout.millis", "timeout.millis", identity) })
+	require.Panics(t, func() { r.RegisterAliasWithTransform("timeout.seconds", "timeout.millis", identity) })
+	require.Panics(t, func() { r.RegisterAliasWithTransform("other", "unknown", identity) })
+}
+
+func TestTouch(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(8), "i"))
+
+	var calls int
+	i2A.SetOnChange(sv, func() { calls++ })
+	var computes int
+	get := settings.Memoize(sv, i2A, func() interface{} {
+		computes++
+		return i2A.Get(sv)
+	})
+	require.Equal(t, int64(8), get())
+
+	require.NoError(t, settings.Touch(sv, "i.2"))
+	require.Equal(t, 1, calls)
+	require.Equal(t, int64(8), i2A.Get(sv))
+	require.Equal(t, int64(8), get())
+	require.Equal(t, 2, computes)
+
+	// Touching another setting doesn't affect this one.
+	require.NoError(t, settings.Touch(sv, "i.1"))
+	require.Equal(t, 1, calls)
+
+	// Touching isn't a change of the value, so it doesn't conflict with
+	// transactions reading the setting, nor is it captured.
+	changes, restore := settings.TestingCaptureChanges(sv)
+	before := settings.CaptureSnapshot(sv).Version()
+	require.NoError(t, settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		v, err := txn.Get("i.2")
+		if err != nil {
+			return err
+		}
+		if err := settings.Touch(sv, "i.2"); err != nil {
+			return err
+		}
+		return txn.Set("i.1", v)
+	}))
+	restore()
+	require.Equal(t, int64(8), i1A.Get(sv))
+	require.Equal(t, []settings.Change{{Key: "i.1", Encoded: "8"}}, *changes)
+	require.Equal(t, 2, calls)
+	require.Equal(t, before+1, settings.CaptureSnapshot(sv).Version())
+
+	require.EqualError(t, settings.Touch(sv, "unknown"), "unknown setting 'unknown'")
+}
+
+func TestEncodeValue(t *testing.T) {
+	testCases := []struct {
+		key      string
+		setting  settings.Setting
+		value    interface{}
+		expected string
+	}{
+		{"i.2", i2A, int64(7), "7"},
+		{"zzz", byteSize, int64(2 << 20), "2097152"},
+		{"e", eA, int64(2), "2"},
+		{"bool.t", boolTA, false, "false"},
+		{"f", fA, 1.5, "1.5"},
+		{"d", dA, 90 * time.Second, "1m30s"},
+		{"d_with_explicit_unit", duA, time.Minute, "1m0s"},
+		{"str.bar", strBarA, "baz", "baz"},
+	}
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			encoded, err := settings.EncodeValue(tc.setting, tc.value)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, encoded)
+			// The encoded value can be set.
+			require.NoError(t, u.Set(tc.key, encoded, tc.setting.Typ()))
+		})
+	}
+
+	_, err := settings.EncodeValue(i2A, 7)
+	require.EqualError(t, err, "cannot encode int as a value of a setting of type i")
+	_, err = settings.EncodeValue(dA, "1s")
+	require.EqualError(t, err, "cannot encode string as a value of a setting of type d")
+	_, err = settings.EncodeValue(mA, "foo")
+	require.EqualError(t, err, "cannot encode values of settings of type *settings.StateMachineSetting")
+}
+
+func TestSnapshot(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	before := settings.CaptureSnapshot(sv)
+	require.Empty(t, settings.CaptureSnapshot(sv).DiffSince(before))
+
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(8), "i"))
+	require.NoError(t, u.Set("d", settings.EncodeDuration(time.Minute), "d"))
+	// Setting a value to the one it already has is not a change.
+	require.NoError(t, u.Set("bool.t", settings.EncodeBool(true), "b"))
+	after := settings.CaptureSnapshot(sv)
+	require.Greater(t, after.Version(), before.Version())
+
+	diff := after.DiffSince(before)
+	require.Equal(t, []settings.Change{
+		{Key: "d", Encoded: "1m0s"},
+		{Key: "i.2", Encoded: "8"},
+	}, diff)
+
+	// Applying the diff to values in the initial state brings them to the same
+	// state.
+	other := &settings.Values{}
+	other.Init(settings.TestOpaque)
+	require.NoError(t, settings.ApplyChanges(other, diff))
+	require.Equal(t, int64(8), i2A.Get(other))
+	require.Empty(t, settings.CaptureSnapshot(other).DiffSince(after))
+	require.Empty(t, after.DiffSince(settings.CaptureSnapshot(other)))
+
+	// Invalid changes are not applied.
+	require.Error(t, settings.ApplyChanges(other, []settings.Change{
+		{Key: "i.2", Encoded: "9"},
+		{Key: "bool.t", Encoded: "maybe"},
+	}))
+	require.Equal(t, int64(8), i2A.Get(other))
+	require.EqualError(t, settings.ApplyChanges(other, []settings.Change{{Key: "unknown"}}),
+		"unknown setting 'unknown'")
+
+	// The changes were accepted where they were made, so settings requiring a
+	// reason are synced too.
+	require.NoError(t, settings.ApplyChanges(other, []settings.Change{
+		{Key: "reason.int", Encoded: "1"},
+	}))
+	require.Equal(t, int64(1), reasonInt.Get(other))
+
+	// A nil snapshot is diffed as an empty one.
+	require.Len(t, after.DiffSince(nil), len(settings.CaptureSnapshot(sv).DiffSince(nil)))
+}
+
+func TestBoolFastValuePtr(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+
+	p := boolFA.FastValuePtr(sv)
+	require.Equal(t, int64(0), atomic.LoadInt64(p))
+	require.NoError(t, u.Set("bool.f", settings.EncodeBool(true), "b"))
+	require.NotEqual(t, int64(0), atomic.LoadInt64(p))
+	settings.NewUpdater(sv).ResetRemaining()
+	require.Equal(t, int64(0), atomic.LoadInt64(p))
+	require.Equal(t, p, boolFA.FastValuePtr(sv))
+}
+
+var protectedInt = settings.RegisterIntSetting("protected.int", "desc", 0)
+var unprotectedInt = settings.RegisterIntSetting("protected.other", "desc", 0)
+
+func init() {
+	protectedInt.SetProtected()
+}
+
+func TestProtectedSetting(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	require.True(t, protectedInt.Protected())
+	require.False(t, unprotectedInt.Protected())
+	s, ok := settings.Lookup("protected.int", settings.LookupForReporting)
+	require.True(t, ok)
+	require.True(t, s.Protected())
+
+	// Privileged callers can change protected settings.
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("protected.int", settings.EncodeInt(1), "i"))
+	require.Equal(t, int64(1), protectedInt.Get(sv))
+
+	// Unprivileged callers can only change the other settings.
+	unprivileged := settings.NewUnprivilegedUpdater(sv)
+	require.EqualError(t, unprivileged.Set("protected.int", settings.EncodeInt(2), "i"),
+		"insufficient privilege to modify 'protected.int'")
+	require.EqualError(t, unprivileged.SetWithReason("protected.int", settings.EncodeInt(2), "i", "why"),
+		"insufficient privilege to modify 'protected.int'")
+	require.Equal(t, int64(1), protectedInt.Get(sv))
+	require.NoError(t, unprivileged.Set("protected.other", settings.EncodeInt(2), "i"))
+	require.Equal(t, int64(2), unprotectedInt.Get(sv))
+
+	require.EqualError(t, unprivileged.ResetPrefix("protected.", false /* includeRetired */),
+		"insufficient privilege to modify 'protected.int'")
+	require.Equal(t, int64(1), protectedInt.Get(sv))
+	require.Equal(t, int64(2), unprotectedInt.Get(sv))
+
+	settings.NewUnprivilegedUpdater(sv).ResetRemaining()
+	require.Equal(t, int64(1), protectedInt.Get(sv))
+	require.Equal(t, int64(0), unprotectedInt.Get(sv))
+
+	require.NoError(t, u.ResetPrefix("protected.", false /* includeRetired */))
+	require.Equal(t, int64(0), protectedInt.Get(sv))
+}
+
+func TestFilter(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("zzz", settings.EncodeInt(2<<30), "z"))
+
+	// Byte size settings currently over 1GiB.
+	large := settings.Filter(func(s settings.Setting) bool {
+		b, ok := s.(*settings.ByteSizeSetting)
+		return ok && b.Get(sv) > 1<<30
+	})
+	require.Equal(t, []settings.Setting{byteSize}, large)
+
+	bools := settings.Filter(func(s settings.Setting) bool { return s.Typ() == "b" })
+	require.Contains(t, bools, boolFA)
+	require.Contains(t, bools, boolTA)
+	for _, s := range bools {
+		require.IsType(t, &settings.BoolSetting{}, s)
+	}
+
+	require.Empty(t, settings.Filter(func(settings.Setting) bool { return false }))
+
+	r := settings.NewRegistry()
+	b := r.RegisterBoolSetting("b", "desc", true)
+	i := r.RegisterIntSetting("a", "desc", 1)
+	require.Equal(t, []settings.Setting{i, b}, r.Filter(func(settings.Setting) bool { return true }))
+}
+
+func TestValidateEncoding(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+
+	testCases := []struct {
+		key         string
+		encoded     string
+		expectedErr string
+	}{
+		{"bool.t", "false", ""},
+		{"bool.t", "maybe", `strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{"i.Val", "3", ""},
+		{"i.Val", "-3", "int cannot be negative"},
+		{"i.Val", "three", `strconv.Atoi: parsing "three": invalid syntax`},
+		{"fVal", "1.5", ""},
+		{"fVal", "-1.5", "cannot set fVal to a negative value: -1.500000"},
+		{"dVal", "1m", ""},
+		{"dVal", "-1m", "cannot set dVal to a negative duration: -1m0s"},
+		{"dVal", "1", `time: missing unit in duration "1"`},
+		{"byteSize.Val", "2MiB", ""},
+		{"e", "2", ""},
+		{"e", "4", "unrecognized value 4"},
+		{"str.val", "abc", ""},
+		{"str.val", "a1", "not all runes of a1 are letters: 1"},
+		{"unknown", "1", "unknown setting 'unknown'"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key+"="+tc.encoded, func(t *testing.T) {
+			before := settings.CaptureSnapshot(sv)
+			err := settings.ValidateEncoding(sv, tc.key, tc.encoded)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
+			// Nothing changed.
+			require.Empty(t, settings.CaptureSnapshot(sv).DiffSince(before))
+		})
+	}
+
+	// State machine settings are validated by their transformer.
+	require.Error(t, settings.ValidateEncoding(sv, "statemachine", "garbage"))
+	require.Nil(t, mA.GetInternal(sv))
+}
+
+func TestDefaultsHash(t *testing.T) {
+	require.Equal(t, settings.DefaultsHash(), settings.DefaultsHash())
+
+	makeRegistry := func(intDefault int64) settings.Registry {
+		r := settings.NewRegistry()
+		r.RegisterBoolSetting("b", "desc", true)
+		r.RegisterIntSetting("i", "desc", intDefault)
+		r.RegisterStringSetting("s", "desc", "foo")
+		return r
+	}
+	// Registries with the same settings have the same hash, regardless of the
+	// order in which the settings were registered.
+	hash := makeRegistry(1).DefaultsHash()
+	require.Equal(t, hash, makeRegistry(1).DefaultsHash())
+	r := settings.NewRegistry()
+	r.RegisterStringSetting("s", "desc", "foo")
+	r.RegisterIntSetting("i", "desc", 1)
+	r.RegisterBoolSetting("b", "desc", true)
+	require.Equal(t, hash, r.DefaultsHash())
+
+	// Changing a default, a type or a key changes the hash.
+	require.NotEqual(t, hash, makeRegistry(2).DefaultsHash())
+	r = settings.NewRegistry()
+	r.RegisterBoolSetting("b", "desc", true)
+	r.RegisterStringSetting("i", "desc", "1")
+	r.RegisterStringSetting("s", "desc", "foo")
+	require.NotEqual(t, hash, r.DefaultsHash())
+	r = settings.NewRegistry()
+	r.RegisterBoolSetting("b", "desc", true)
+	r.RegisterIntSetting("j", "desc", 1)
+	r.RegisterStringSetting("s", "desc", "foo")
+	require.NotEqual(t, hash, r.DefaultsHash())
+
+	// Defaults computed at runtime, e.g. from the number of CPUs, can differ
+	// between nodes running the same binary and don't affect the hash.
+	makeDynamicRegistry := func(intDefault int64) settings.Registry {
+		r := settings.NewRegistry()
+		r.RegisterIntSettingWithDynamicDefault("i", "desc", func() int64 { return intDefault })
+		return r
+	}
+	r1, r2 := makeDynamicRegistry(1), makeDynamicRegistry(2)
+	var sv1, sv2 settings.Values
+	r1.InitValues(&sv1, settings.TestOpaque)
+	r2.InitValues(&sv2, settings.TestOpaque)
+	require.Equal(t, r1.DefaultsHash(), r2.DefaultsHash())
+	// They are still distinguished from a constant default.
+	r = settings.NewRegistry()
+	r.RegisterIntSetting("i", "desc", 1)
+	require.NotEqual(t, r1.DefaultsHash(), r.DefaultsHash())
+}
+
+func TestErrorHandler(t *testing.T) {
+	type failure struct {
+		key string
+		err string
+	}
+	var failures []failure
+	settings.SetErrorHandler(func(key string, err error) {
+		failures = append(failures, failure{key: key, err: err.Error()})
+	})
+	defer settings.SetErrorHandler(nil)
+
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	var ran bool
+	i1A.SetOnChange(sv, func() { panic("boom") })
+	i1A.SetOnChange(sv, func() { panic(errors.New("kaboom")) })
+	i1A.SetOnChange(sv, func() { ran = true })
+
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(3), "i"))
+	// The value is changed and the later callbacks run despite the panics.
+	require.Equal(t, int64(3), i1A.Get(sv))
+	require.True(t, ran)
+	require.Equal(t, []failure{
+		{key: "i.1", err: "panic in OnChange callback: boom"},
+		{key: "i.1", err: "panic in OnChange callback: kaboom"},
+	}, failures)
+}
+
+var workerCountCalls int32
+var workerCount = settings.RegisterIntSettingWithDynamicDefault("worker.count", "desc",
+	func() int64 {
+		atomic.AddInt32(&workerCountCalls, 1)
+		return int64(runtime.GOMAXPROCS(0)) * 2
+	})
+
+func TestIntSettingWithDynamicDefault(t *testing.T) {
+	expected := int64(runtime.GOMAXPROCS(0)) * 2
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	require.Equal(t, expected, workerCount.Get(sv))
+	require.Equal(t, expected, workerCount.Default())
+	s, ok := settings.Lookup("worker.count", settings.LookupForLocalAccess)
+	require.True(t, ok)
+	require.Equal(t, settings.EncodeInt(expected), s.(*settings.IntSetting).EncodedDefault())
+
+	// Reverting to the default uses the computed value.
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("worker.count", settings.EncodeInt(expected+1), "i"))
+	require.Equal(t, expected+1, workerCount.Get(sv))
+	settings.NewUpdater(sv).ResetRemaining()
+	require.Equal(t, expected, workerCount.Get(sv))
+
+	// The default is only computed once.
+	sv2 := &settings.Values{}
+	sv2.Init(settings.TestOpaque)
+	require.Equal(t, expected, workerCount.Get(sv2))
+	require.Equal(t, int32(1), atomic.LoadInt32(&workerCountCalls))
+}
+
+func TestTestingRegister(t *testing.T) {
+	numSettings := settings.NumRegisteredSettings()
+	var sv settings.Values
+	var i *settings.IntSetting
+	var changes int
+	func() {
+		b, unregisterB := settings.TestingRegisterBool(t, "scoped.bool", "desc", true)
+		defer unregisterB()
+		var unregisterI func()
+		i, unregisterI = settings.TestingRegisterInt(t, "scoped.int", "desc", 7)
+		defer unregisterI()
+		require.Equal(t, numSettings+2, settings.NumRegisteredSettings())
+
+		sv.Init(settings.TestOpaque)
+		require.True(t, b.Get(&sv))
+		require.Equal(t, int64(7), i.Get(&sv))
+		i.SetOnChange(&sv, func() { changes++ })
+		u := settings.NewUpdater(&sv)
+		require.NoError(t, u.Set("scoped.int", settings.EncodeInt(8), "i"))
+		require.Equal(t, int64(8), i.Get(&sv))
+		require.Equal(t, 1, changes)
+		_, ok := settings.Lookup("scoped.bool", settings.LookupForLocalAccess)
+		require.True(t, ok)
+
+		// Settings can be unregistered in any order, and more than once.
+		unregisterB()
+	}()
+
+	require.Equal(t, numSettings, settings.NumRegisteredSettings())
+	_, ok := settings.Lookup("scoped.bool", settings.LookupForLocalAccess)
+	require.False(t, ok)
+	_, ok = settings.Lookup("scoped.int", settings.LookupForLocalAccess)
+	require.False(t, ok)
+
+	// The keys can be registered again, and the settings registered next reuse
+	// the slots without inheriting the state of the previous settings in the
+	// Values initialized while those were registered.
+	s, unregisterS := settings.TestingRegisterString(t, "scoped.bool", "desc", "foo")
+	defer unregisterS()
+	j, unregisterJ := settings.TestingRegisterInt(t, "scoped.other", "desc", 3)
+	defer unregisterJ()
+	require.Equal(t, numSettings+2, settings.NumRegisteredSettings())
+	require.Equal(t, int64(0), j.Get(&sv))
+	u := settings.NewUpdater(&sv)
+	require.NoError(t, u.Set("scoped.other", settings.EncodeInt(4), "i"))
+	require.NoError(t, u.Set("scoped.bool", "bar", "s"))
+	require.Equal(t, int64(4), j.Get(&sv))
+	require.Equal(t, "bar", s.Get(&sv))
+	require.Equal(t, 1, changes)
+
+	sv2 := &settings.Values{}
+	sv2.Init(settings.TestOpaque)
+	require.Equal(t, "foo", s.Get(sv2))
+	require.Equal(t, int64(3), j.Get(sv2))
+
+	// Where the testing package supports it, the settings are unregistered at
+	// the end of the test without calling the returned function.
+	if _, ok := interface{}(t).(interface{ Cleanup(func()) }); ok {
+		t.Run("cleanup", func(t *testing.T) {
+			settings.TestingRegisterFloat(t, "scoped.float", "desc", 1.5)
+			_, ok := settings.Lookup("scoped.float", settings.LookupForLocalAccess)
+			require.True(t, ok)
+		})
+		_, ok := settings.Lookup("scoped.float", settings.LookupForLocalAccess)
+		require.False(t, ok)
+	}
+}
+
+// TestTestingRegisterUntracked checks that the slot of a setting with callbacks
+// in Values initialized before any setting was registered through the
+// TestingRegister functions is not reused, so that the callbacks never fire
+// for another setting.
+func TestTestingRegisterUntracked(t *testing.T) {
+	var early settings.Values
+	early.Init(settings.TestOpaque)
+	var changes int
+	f, unregisterF := settings.TestingRegisterInt(t, "scoped.early", "desc", 1)
+	f.SetOnChange(&early, func() { changes++ })
+	unregisterF()
+
+	g, unregisterG := settings.TestingRegisterInt(t, "scoped.late", "desc", 2)
+	defer unregisterG()
+	require.NoError(t, settings.NewUpdater(&early).Set("scoped.late", settings.EncodeInt(5), "i"))
+	require.Equal(t, int64(5), g.Get(&early))
+	require.Equal(t, 0, changes)
+}
+
+func TestMergeOverrides(t *testing.T) {
+	base := map[string]string{"i.1": "1", "i.2": "2", "str.bar": "base"}
+	overlay := map[string]string{"i.2": "20", "d": "1m", "str.bar": ""}
+	require.Equal(t, map[string]string{
+		"i.1":     "1",
+		"i.2":     "20",
+		"d":       "1m",
+		"str.bar": "",
+	}, settings.MergeOverrides(base, overlay))
+
+	// The inputs are left untouched.
+	require.Equal(t, map[string]string{"i.1": "1", "i.2": "2", "str.bar": "base"}, base)
+	require.Len(t, overlay, 3)
+
+	// Values are not validated.
+	require.Equal(t, map[string]string{"dne": "x"},
+		settings.MergeOverrides(nil, map[string]string{"dne": "x"}))
+	require.Equal(t, base, settings.MergeOverrides(base, nil))
+	require.Empty(t, settings.MergeOverrides(nil, nil))
+}
+
+func TestRegisterDependent(t *testing.T) {
+	var failures []string
+	settings.SetErrorHandler(func(key string, err error) {
+		failures = append(failures, fmt.Sprintf("%s: %v", key, err))
+	})
+	defer settings.SetErrorHandler(nil)
+
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	var recomputes int
+	var recomputeErr error
+	settings.RegisterDependent(sv, i2A, []settings.WritableSetting{i1A, boolFA}, func() error {
+		recomputes++
+		if recomputeErr != nil {
+			return recomputeErr
+		}
+		// The child is derived from the parents, and was reset by the time
+		// recompute runs.
+		if i2A.Get(sv) != 5 {
+			return errors.Errorf("child not reset: %d", i2A.Get(sv))
+		}
+		v := i1A.Get(sv)
+		if boolFA.Get(sv) {
+			v *= 2
+		}
+		return u.Set("i.2", settings.EncodeInt(v), "i")
+	})
+
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(10), "i"))
+	require.Equal(t, 1, recomputes)
+	require.Equal(t, int64(10), i2A.Get(sv))
+	require.NoError(t, u.Set("bool.f", settings.EncodeBool(true), "b"))
+	require.Equal(t, 2, recomputes)
+	require.Equal(t, int64(20), i2A.Get(sv))
+	// Changes of other settings, including the child, don't trigger it.
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(7), "i"))
+	require.NoError(t, u.Set("i.Val", settings.EncodeInt(7), "i"))
+	require.Equal(t, 2, recomputes)
+	require.Equal(t, int64(7), i2A.Get(sv))
+
+	// A single commit changing both parents recomputes once.
+	require.NoError(t, settings.NewBatch().SetInt(i1A, 3).SetBool(boolFA, false).Apply(sv))
+	require.Equal(t, 3, recomputes)
+	require.Equal(t, int64(3), i2A.Get(sv))
+	require.NoError(t, settings.SetMulti(sv, map[string]settings.EncodedValue{
+		"i.1":    {Raw: settings.EncodeInt(4), Type: "i"},
+		"bool.f": {Raw: settings.EncodeBool(true), Type: "b"},
+	}, "" /* reason */))
+	require.Equal(t, 4, recomputes)
+	require.Equal(t, int64(8), i2A.Get(sv))
+	require.NoError(t, settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		if err := txn.Set("i.1", settings.EncodeInt(6)); err != nil {
+			return err
+		}
+		return txn.Set("bool.f", settings.EncodeBool(false))
+	}))
+	require.Equal(t, 5, recomputes)
+	require.Equal(t, int64(6), i2A.Get(sv))
+
+	// On failure, the child is left at its default.
+	recomputeErr = errors.New("boom")
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(11), "i"))
+	require.Equal(t, 6, recomputes)
+	require.Equal(t, []string{"i.2: recomputing dependent setting: boom"}, failures)
+	require.Equal(t, int64(11), i1A.Get(sv))
+	require.Equal(t, int64(5), i2A.Get(sv))
+}
+
+func TestRunInTxn(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.1", settings.EncodeInt(10), "i"))
+
+	// Read-modify-write: move 3 from i.1 to i.2.
+	transfer := func(txn *settings.Txn) error {
+		from, err := txn.Get("i.1")
+		if err != nil {
+			return err
+		}
+		to, err := txn.Get("i.2")
+		if err != nil {
+			return err
+		}
+		f, _ := strconv.ParseInt(from, 10, 64)
+		g, _ := strconv.ParseInt(to, 10, 64)
+		if err := txn.Set("i.1", settings.EncodeInt(f-3)); err != nil {
+			return err
+		}
+		return txn.Set("i.2", settings.EncodeInt(g+3))
+	}
+	require.NoError(t, settings.RunInTxn(sv, "rebalance", transfer))
+	require.Equal(t, int64(7), i1A.Get(sv))
+	require.Equal(t, int64(8), i2A.Get(sv))
+	_, reason, _ := settings.ValueAndReason(sv, "i.2")
+	require.Equal(t, "rebalance", reason)
+
+	// Settings requiring a reason can't be changed without one.
+	require.EqualError(t, settings.RunInTxn(sv, "" /* reason */, func(txn *settings.Txn) error {
+		require.NoError(t, txn.Set("i.1", settings.EncodeInt(1)))
+		return txn.Set("reason.int", settings.EncodeInt(1))
+	}), "failed to set reason.int: setting 'reason.int' requires a reason")
+	require.Equal(t, int64(7), i1A.Get(sv))
+
+	// The transaction sees its own writes.
+	require.NoError(t, settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		require.NoError(t, txn.Set("str.bar", "baz"))
+		v, err := txn.Get("str.bar")
+		require.NoError(t, err)
+		require.Equal(t, "baz", v)
+		return nil
+	}))
+	require.Equal(t, "baz", strBarA.Get(sv))
+
+	// Errors discard the writes.
+	require.EqualError(t, settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		require.NoError(t, txn.Set("i.1", settings.EncodeInt(100)))
+		return errors.New("boom")
+	}), "boom")
+	require.Equal(t, int64(7), i1A.Get(sv))
+
+	// Invalid values discard all the writes.
+	require.Error(t, settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		require.NoError(t, txn.Set("i.1", settings.EncodeInt(100)))
+		return txn.Set("i.Val", settings.EncodeInt(-1))
+	}))
+	require.Equal(t, int64(7), i1A.Get(sv))
+
+	// A concurrent change of a setting read by the transaction is a conflict.
+	err := settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		if err := transfer(txn); err != nil {
+			return err
+		}
+		return settings.NewUpdater(sv).Set("i.2", settings.EncodeInt(0), "i")
+	})
+	require.True(t, errors.Is(err, settings.ErrTxnConflict), "%+v", err)
+	require.Equal(t, int64(7), i1A.Get(sv))
+	require.Equal(t, int64(0), i2A.Get(sv))
+
+	require.EqualError(t, settings.RunInTxn(sv, "test", func(txn *settings.Txn) error {
+		return txn.Set("dne", "1")
+	}), "unknown setting 'dne'")
+}
+
+func TestRegisterGauge(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	gauges := make(map[string]func() float64)
+	register := func(name string, get func() float64) { gauges[name] = get }
+
+	for _, key := range []string{"i.2", "bool.t", "e", "f", "d", "zzz"} {
+		require.NoError(t, settings.RegisterGauge(sv, key, register))
+	}
+	require.EqualError(t, settings.RegisterGauge(sv, "str.bar", register),
+		"setting 'str.bar' of type s cannot be published as a gauge")
+	require.EqualError(t, settings.RegisterGauge(sv, "dne", register), "unknown setting 'dne'")
+
+	get := func(key string) float64 { return gauges[settings.MetricName(key)]() }
+	require.Equal(t, float64(5), get("i.2"))
+	require.Equal(t, float64(1), get("bool.t"))
+	require.Equal(t, float64(eA.Get(sv)), get("e"))
+	require.Equal(t, float64(time.Second), get("d"))
+
+	u := settings.NewUpdater(sv)
+	require.NoError(t, u.Set("i.2", settings.EncodeInt(42), "i"))
+	require.NoError(t, u.Set("bool.t", settings.EncodeBool(false), "b"))
+	require.NoError(t, u.Set("f", settings.EncodeFloat(1.5), "f"))
+	require.NoError(t, u.Set("e", settings.EncodeInt(2), "e"))
+	require.Equal(t, float64(42), get("i.2"))
+	require.Equal(t, float64(0), get("bool.t"))
+	require.Equal(t, 1.5, get("f"))
+	require.Equal(t, float64(2), get("e"))
+}
+
+func TestTestingWaitForCallbacks(t *testing.T) {
+	sv := &settings.Values{}
+	sv.Init(settings.TestOpaque)
+	require.Error(t, settings.TestingWaitForCallbacks(sv, 1, time.Millisecond))
+
+	defer settings.TestingTrackCallbacks(sv)()
+	seq := settings.TestingLastCommit(sv)
+	require.Equal(t, int64(0), seq)
+	require.NoError(t, settings.TestingWaitForCallbacks(sv, seq, time.Millisecond))
+
+	var done int32
+	i2A.SetOnChange(sv, func() {
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&done, 1)
+	})
+	// The next commit hasn't started yet.
+	require.EqualError(t, settings.TestingWaitForCallbacks(sv, seq+1, time.Millisecond),
+		"settings commit 1 not finished after 1ms")
+
+	start := make(chan struct{})
+	go func() {
+		<-start
+		_ = settings.NewUpdater(sv).Set("i.2", settings.EncodeInt(7), "i")
+	}()
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- settings.TestingWaitForCallbacks(sv, seq+1, 10*time.Second)
+	}()
+	close(start)
+	require.NoError(t, <-waitErr)
+	require.Equal(t, int32(1), atomic.LoadInt32(&done))
+	require.Equal(t, int64(7), i2A.Get(sv))
+	require.Equal(t, seq+1, settings.TestingLastCommit(sv))
+}
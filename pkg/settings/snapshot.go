@@ -0,0 +1,103 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Snapshot is the state of the settings of a Values at some point, which can
+// be diffed against another Snapshot so that only the settings that changed
+// need to be propagated, e.g. between nodes; see ApplyChanges.
+//
+// State machine settings are not included, as they have their own upgrade
+// machinery.
+type Snapshot struct {
+	version int64
+	values  map[string]string
+}
+
+// CaptureSnapshot returns a Snapshot of the settings in sv.
+func CaptureSnapshot(sv *Values) *Snapshot {
+	r := sv.getRegistry()
+	snap := &Snapshot{values: make(map[string]string, len(r.settings))}
+	for k, s := range r.settings {
+		snap.version += atomic.LoadInt64(&sv.epochs[s.getSlotIdx()-1])
+		if _, ok := s.(*StateMachineSetting); ok {
+			continue
+		}
+		snap.values[k] = s.Encoded(sv)
+	}
+	return snap
+}
+
+// Version returns the number of changes made to the settings of the Values up
+// to when the Snapshot was captured. Later snapshots of the same Values have
+// higher or equal versions.
+func (s *Snapshot) Version() int64 {
+	return s.version
+}
+
+// DiffSince returns, sorted by key, the changes that turn the snapshot other,
+// which may be nil, into s. Settings missing from s are not reported.
+func (s *Snapshot) DiffSince(other *Snapshot) []Change {
+	var changes []Change
+	for k, v := range s.values {
+		if other != nil {
+			if otherV, ok := other.values[k]; ok && otherV == v {
+				continue
+			}
+		}
+		changes = append(changes, Change{Key: k, Encoded: v})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// ApplyChanges applies changes, e.g. as returned by Snapshot.DiffSince, to the
+// settings in sv. The changes are validated first, as with SetMulti, and none
+// of them is applied if any is invalid. As with NewRefreshUpdater, the changes
+// are taken to have been accepted where they were made, so the settings marked
+// with SetRequireReason are changed without a reason.
+func ApplyChanges(sv *Values, changes []Change) error {
+	r := sv.getRegistry()
+	values := make(map[string]EncodedValue, len(changes))
+	for _, c := range changes {
+		s, ok := r.settings[c.Key]
+		if !ok {
+			return errors.Errorf("unknown setting '%s'", c.Key)
+		}
+		values[c.Key] = EncodedValue{Raw: c.Encoded, Type: s.Typ()}
+	}
+	u := NewRefreshUpdater(sv).(updater)
+	u.provenance = ProvenanceSync
+	return sv.commit(func() error { return u.setMulti(values, "" /* reason */) })
+}
+
+// MergeOverrides combines two sets of encoded setting values keyed by setting,
+// e.g. the overrides from a cluster-wide policy (base) and those of the local
+// node (overlay), into a new map. The value from overlay wins for the keys
+// present in both. The merge is purely syntactic: neither the keys nor the
+// values are validated, which only happens when the result is applied, e.g.
+// through ApplyChanges. Neither argument is modified, and either may be nil.
+func MergeOverrides(base, overlay map[string]string) map[string]string {
+	res := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		res[k] = v
+	}
+	for k, v := range overlay {
+		res[k] = v
+	}
+	return res
+}
@@ -72,6 +72,14 @@ func (s *StringSetting) set(sv *Values, v string) error {
 }
 
 func (s *StringSetting) setToDefault(sv *Values) {
+	// See if the default value was overridden.
+	ok, _, generic := sv.getDefaultOverride(s.slotIdx)
+	if ok {
+		// As per the semantics of override, these values don't go through
+		// validation.
+		_ = s.set(sv, generic.(string))
+		return
+	}
 	if err := s.set(sv, s.defaultValue); err != nil {
 		panic(err)
 	}
@@ -82,6 +90,15 @@ func RegisterStringSetting(key, desc string, defaultValue string) *StringSetting
 	return RegisterValidatedStringSetting(key, desc, defaultValue, nil)
 }
 
+// RegisterStringSetting implements the Registry interface.
+func (r *registry) RegisterStringSetting(key, desc string, defaultValue string) *StringSetting {
+	setting := &StringSetting{defaultValue: defaultValue}
+	// See RegisterValidatedStringSetting.
+	setting.SetReportable(false)
+	r.register(key, desc, setting)
+	return setting
+}
+
 // RegisterPublicStringSetting defines a new setting with type string and makes it public.
 func RegisterPublicStringSetting(key, desc string, defaultValue string) *StringSetting {
 	s := RegisterValidatedStringSetting(key, desc, defaultValue, nil)
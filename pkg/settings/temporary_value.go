@@ -0,0 +1,41 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import "github.com/cockroachdb/errors"
+
+// WithTemporaryValue sets s in sv to the given encoded value, runs fn, and then
+// restores the previous value of s, even if fn panics. An error is returned,
+// without running fn, if the value cannot be set, or if the previous value
+// could not be restored.
+func WithTemporaryValue(sv *Values, s Setting, encodedVal string, fn func()) (err error) {
+	es, ok := s.(extendedSetting)
+	if !ok {
+		return errors.AssertionFailedf("cannot set a %T", s)
+	}
+	r := sv.getRegistry()
+	key, ok := r.keyOf(es)
+	if !ok {
+		return errors.AssertionFailedf("setting is not registered")
+	}
+	prev := es.Encoded(sv)
+	u := r.MakeUpdater(sv)
+	if err := u.Set(key, encodedVal, es.Typ()); err != nil {
+		return err
+	}
+	defer func() {
+		if restoreErr := u.Set(key, prev, es.Typ()); restoreErr != nil {
+			err = errors.Wrapf(restoreErr, "restoring setting '%s'", key)
+		}
+	}()
+	fn()
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestingRegisterBool registers a bool setting in the default registry for
+// the duration of the test t. Unlike settings registered into package-level
+// variables, such settings don't leak into other tests nor permanently take
+// one of the MaxSettings slots.
+//
+// The setting is unregistered through t.Cleanup when the testing package
+// provides it, which requires Go 1.14. The Go 1.13 toolchain this repository
+// currently builds with doesn't, so the returned function, which unregisters
+// the setting, must be deferred as well; calling it more than once is
+// harmless.
+func TestingRegisterBool(
+	t testing.TB, key, desc string, defaultValue bool,
+) (*BoolSetting, func()) {
+	return RegisterBoolSetting(key, desc, defaultValue), testingUnregister(t, key)
+}
+
+// TestingRegisterInt is like TestingRegisterBool, for int settings.
+func TestingRegisterInt(
+	t testing.TB, key, desc string, defaultValue int64,
+) (*IntSetting, func()) {
+	return RegisterIntSetting(key, desc, defaultValue), testingUnregister(t, key)
+}
+
+// TestingRegisterFloat is like TestingRegisterBool, for float settings.
+func TestingRegisterFloat(
+	t testing.TB, key, desc string, defaultValue float64,
+) (*FloatSetting, func()) {
+	return RegisterFloatSetting(key, desc, defaultValue), testingUnregister(t, key)
+}
+
+// TestingRegisterDuration is like TestingRegisterBool, for duration settings.
+func TestingRegisterDuration(
+	t testing.TB, key, desc string, defaultValue time.Duration,
+) (*DurationSetting, func()) {
+	return RegisterDurationSetting(key, desc, defaultValue), testingUnregister(t, key)
+}
+
+// TestingRegisterString is like TestingRegisterBool, for string settings.
+func TestingRegisterString(
+	t testing.TB, key, desc string, defaultValue string,
+) (*StringSetting, func()) {
+	return RegisterStringSetting(key, desc, defaultValue), testingUnregister(t, key)
+}
+
+// testingUnregister returns a function unregistering the setting with the
+// given key from the default registry, and arranges for it to run at the end
+// of the test t if possible.
+func testingUnregister(t testing.TB, key string) func() {
+	defaultRegistry.mu.Lock()
+	defaultRegistry.scoped.count++
+	defaultRegistry.mu.Unlock()
+	var once sync.Once
+	unregister := func() {
+		once.Do(func() { defaultRegistry.unregister(key) })
+	}
+	// Cleanup was added to testing.TB in Go 1.14.
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(unregister)
+	}
+	return unregister
+}
+
+// unregister removes the setting with the given key, registered through one
+// of the TestingRegister functions, from the registry. Its slot is cleared in
+// the Values initialized while it was registered, and is reused by the next
+// setting registered unless Values initialized earlier still have callbacks,
+// watchers or dependents installed for it, which would otherwise carry over
+// to that setting.
+func (r *registry) unregister(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.settings[key]
+	if !ok {
+		panic(fmt.Sprintf("setting not defined: %s", key))
+	}
+	delete(r.settings, key)
+	slotIdx := s.getSlotIdx()
+	for _, sv := range r.scoped.values {
+		sv.clearSlot(slotIdx)
+	}
+	if atomic.LoadInt32(&r.slotCallbacks[slotIdx-1]) == 0 {
+		r.freeSlots = append(r.freeSlots, slotIdx)
+	}
+	r.scoped.count--
+	if r.scoped.count == 0 {
+		r.scoped.values = nil
+	}
+}
+
+// trackScoped records that sv was initialized by the registry, so that the
+// slots of the settings registered through the TestingRegister functions can
+// be cleared in sv when they are unregistered.
+func (r *registry) trackScoped(sv *Values) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.scoped.count > 0 {
+		r.scoped.values = append(r.scoped.values, sv)
+	}
+}
+
+// addSlotCallbacks records that delta callbacks, watchers or dependents were
+// installed (or, if negative, removed) for the setting in the given slot.
+func (r *registry) addSlotCallbacks(slotIdx int, delta int32) {
+	atomic.AddInt32(&r.slotCallbacks[slotIdx-1], delta)
+}
+
+// clearSlot resets the state in sv of the setting in the given slot, so that
+// it isn't inherited by another setting later assigned the same slot.
+func (sv *Values) clearSlot(slotIdx int) {
+	i := slotIdx - 1
+	atomic.StoreInt64(&sv.container.intVals[i], 0)
+	sv.container.setGenericVal(i, nil)
+	atomic.StoreInt64(&sv.epochs[i], 0)
+	atomic.StoreInt64(&sv.notifications[i], 0)
+	sv.reasons[i].Store(valueWithReason{})
+	sv.clearDefaultOverride(slotIdx)
+
+	r := sv.getRegistry()
+	sv.changeMu.Lock()
+	r.addSlotCallbacks(slotIdx, -int32(len(sv.changeMu.onChange[i])))
+	sv.changeMu.onChange[i] = nil
+	watchers := sv.changeMu.watchers[i]
+	sv.changeMu.watchers[i] = nil
+	r.addSlotCallbacks(slotIdx, -int32(len(watchers)))
+	sv.changeMu.Unlock()
+	for _, w := range watchers {
+		w.stop()
+	}
+	sv.removeDependents(slotIdx)
+}
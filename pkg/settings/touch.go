@@ -0,0 +1,32 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import "github.com/cockroachdb/errors"
+
+// Touch notifies the consumers of the setting with the given key in sv of a
+// change without changing its value: the OnChange callbacks are run, the
+// watchers are notified and the results of Memoize are recomputed. Get keeps
+// returning the same value; Touch is meant for cases where consumers derive
+// state from the setting along with something else that changed, and should
+// re-derive it.
+//
+// Since the value doesn't change, Touch isn't recorded as a change: it doesn't
+// conflict with the transactions that read the setting (see RunInTxn), and
+// doesn't show up in TestingCaptureChanges or in snapshots.
+func Touch(sv *Values, key string) error {
+	s, ok := sv.getRegistry().settings[key]
+	if !ok {
+		return errors.Errorf("unknown setting '%s'", key)
+	}
+	sv.notifyChange(s.getSlotIdx())
+	return nil
+}
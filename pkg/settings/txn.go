@@ -0,0 +1,125 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import (
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrTxnConflict is returned by RunInTxn when a setting read by the
+// transaction changed before the transaction could commit.
+var ErrTxnConflict = errors.New("settings transaction conflicts with a concurrent change")
+
+// Txn is a settings transaction; see RunInTxn.
+type Txn struct {
+	sv     *Values
+	reason string
+	// reads holds the encoded values read by the transaction along with the
+	// epochs of the settings when they were read.
+	reads map[string]txnRead
+	// writes holds the encoded values written by the transaction.
+	writes map[string]string
+}
+
+type txnRead struct {
+	epoch   int64
+	encoded string
+}
+
+// RunInTxn runs fn with a transaction on the settings in sv, which fn can use
+// to read settings and to make changes that are buffered until fn returns.
+// If fn returns an error, the changes are discarded. Otherwise, they are
+// committed as with SetMulti, recording the given reason for all of them:
+// either all of them are applied or, if any is invalid or changes a setting
+// marked with SetRequireReason without a reason, none are.
+//
+// The commit fails with ErrTxnConflict if any of the settings read by the
+// transaction changed in the meantime, so the reads are consistent with each
+// other and with the changes that depend on them; the caller can then retry.
+// The check and the changes are made while holding the lock that the changes
+// made through Updaters take, so none of those can land in between.
+func RunInTxn(sv *Values, reason string, fn func(txn *Txn) error) error {
+	txn := &Txn{sv: sv, reason: reason, reads: make(map[string]txnRead), writes: make(map[string]string)}
+	if err := fn(txn); err != nil {
+		return err
+	}
+	return txn.commit()
+}
+
+// Get returns the encoded value of the setting with the given key as seen by
+// the transaction, including its own changes.
+func (txn *Txn) Get(key string) (string, error) {
+	if v, ok := txn.writes[key]; ok {
+		return v, nil
+	}
+	if r, ok := txn.reads[key]; ok {
+		return r.encoded, nil
+	}
+	s, err := txn.lookup(key)
+	if err != nil {
+		return "", err
+	}
+	epoch := &txn.sv.epochs[s.getSlotIdx()-1]
+	for {
+		e := atomic.LoadInt64(epoch)
+		encoded := s.Encoded(txn.sv)
+		// Retry if the value changed while it was being read, since the value
+		// read may then be newer than the epoch.
+		if atomic.LoadInt64(epoch) == e {
+			txn.reads[key] = txnRead{epoch: e, encoded: encoded}
+			return encoded, nil
+		}
+	}
+}
+
+// Set buffers a change of the setting with the given key to the given encoded
+// value, which is validated when the transaction commits.
+func (txn *Txn) Set(key, encoded string) error {
+	if _, err := txn.lookup(key); err != nil {
+		return err
+	}
+	txn.writes[key] = encoded
+	return nil
+}
+
+func (txn *Txn) lookup(key string) (extendedSetting, error) {
+	s, ok := txn.sv.getRegistry().settings[key]
+	if !ok {
+		return nil, errors.Errorf("unknown setting '%s'", key)
+	}
+	if _, ok := s.(*StateMachineSetting); ok {
+		return nil, errors.Errorf("state machine setting '%s' cannot be used in a transaction", key)
+	}
+	return s, nil
+}
+
+func (txn *Txn) commit() error {
+	if len(txn.writes) == 0 {
+		return nil
+	}
+	r := txn.sv.getRegistry()
+	values := make(map[string]EncodedValue, len(txn.writes))
+	for k, v := range txn.writes {
+		values[k] = EncodedValue{Raw: v, Type: r.settings[k].Typ()}
+	}
+	return txn.sv.commit(func() error {
+		for k, read := range txn.reads {
+			if atomic.LoadInt64(&txn.sv.epochs[r.settings[k].getSlotIdx()-1]) != read.epoch {
+				return errors.Wrapf(ErrTxnConflict, "setting '%s' changed", k)
+			}
+		}
+		u := r.MakeUpdater(txn.sv).(updater)
+		u.provenance = ProvenanceTxn
+		return u.setMulti(values, txn.reason)
+	})
+}
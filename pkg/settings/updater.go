@@ -12,6 +12,7 @@ package settings
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -19,7 +20,7 @@ import (
 
 // EncodeDuration encodes a duration in the format parseRaw expects.
 func EncodeDuration(d time.Duration) string {
-	return d.String()
+	return FormatDuration(d)
 }
 
 // EncodeBool encodes a bool in the format parseRaw expects.
@@ -37,9 +38,61 @@ func EncodeFloat(f float64) string {
 	return strconv.FormatFloat(f, 'G', -1, 64)
 }
 
+// EncodeValue encodes v, a value of the setting s of the type returned by its
+// Get method, in the format parseRaw expects. It returns an error if v is not
+// of that type, or if s is a state machine setting, whose values can't be
+// encoded without the setting's transformer.
+func EncodeValue(s Setting, v interface{}) (string, error) {
+	var ok bool
+	var encoded string
+	switch s.(type) {
+	case *IntSetting, *ByteSizeSetting, *EnumSetting:
+		var i int64
+		if i, ok = v.(int64); ok {
+			encoded = EncodeInt(i)
+		}
+	case *BoolSetting:
+		var b bool
+		if b, ok = v.(bool); ok {
+			encoded = EncodeBool(b)
+		}
+	case *FloatSetting:
+		var f float64
+		if f, ok = v.(float64); ok {
+			encoded = EncodeFloat(f)
+		}
+	case *DurationSetting, *DurationSettingWithExplicitUnit:
+		var d time.Duration
+		if d, ok = v.(time.Duration); ok {
+			encoded = EncodeDuration(d)
+		}
+	case *StringSetting:
+		encoded, ok = v.(string)
+	default:
+		return "", errors.Errorf("cannot encode values of settings of type %T", s)
+	}
+	if !ok {
+		return "", errors.Errorf("cannot encode %T as a value of a setting of type %s", v, s.Typ())
+	}
+	return encoded, nil
+}
+
 type updater struct {
+	r  *registry
 	sv *Values
 	m  map[string]struct{}
+	// unprivileged is set if the updater rejects changes to protected
+	// settings; see NewUnprivilegedUpdater.
+	unprivileged bool
+	// defaultReason is the reason recorded for changes made without one; see
+	// NewUpdaterWithContext.
+	defaultReason string
+	// refresh is set if the updater applies values that were already
+	// accepted, so it doesn't enforce SetRequireReason; see NewRefreshUpdater.
+	refresh bool
+	// provenance is recorded in the change log for the changes made by the
+	// updater.
+	provenance Provenance
 }
 
 // Updater is a helper for updating the in-memory settings.
@@ -50,6 +103,8 @@ type updater struct {
 // then set the rest to default in ResetRemaining().
 type Updater interface {
 	Set(k, rawValue, valType string) error
+	SetWithReason(k, rawValue, valType, reason string) error
+	ResetPrefix(prefix string, includeRetired bool) error
 	ResetRemaining()
 }
 
@@ -59,20 +114,82 @@ type NoopUpdater struct{}
 // Set implements Updater. It is a no-op.
 func (u NoopUpdater) Set(_, _, _ string) error { return nil }
 
+// SetWithReason implements Updater. It is a no-op.
+func (u NoopUpdater) SetWithReason(_, _, _, _ string) error { return nil }
+
+// ResetPrefix implements Updater. It is a no-op.
+func (u NoopUpdater) ResetPrefix(_ string, _ bool) error { return nil }
+
 // ResetRemaining implements Updater. It is a no-op.
 func (u NoopUpdater) ResetRemaining() {}
 
-// NewUpdater makes an Updater.
+// NewUpdater makes an Updater for the settings of the default registry.
 func NewUpdater(sv *Values) Updater {
+	return defaultRegistry.MakeUpdater(sv)
+}
+
+// NewUnprivilegedUpdater makes an Updater for the settings in sv on behalf of
+// a caller without elevated privileges: it rejects changes to the settings
+// marked with SetProtected, and leaves them alone in ResetRemaining.
+func NewUnprivilegedUpdater(sv *Values) Updater {
+	u := sv.getRegistry().MakeUpdater(sv).(updater)
+	u.unprivileged = true
+	return u
+}
+
+// NewRefreshUpdater makes an Updater for the settings in sv that applies
+// values which were already accepted when they were written, e.g. the rows of
+// system.settings read by RefreshSettings. Unlike the other Updaters, it
+// doesn't reject changes made without a reason to the settings marked with
+// SetRequireReason, since the reason was required by the write that persisted
+// the value.
+func NewRefreshUpdater(sv *Values) Updater {
+	u := sv.getRegistry().MakeUpdater(sv).(updater)
+	u.refresh = true
+	u.provenance = ProvenanceRefresh
+	return u
+}
+
+// checkPrivilege returns an error if the updater cannot change the setting s
+// with the given key.
+func (u updater) checkPrivilege(key string, s extendedSetting) error {
+	if u.unprivileged && s.Protected() {
+		return errors.Errorf("insufficient privilege to modify '%s'", key)
+	}
+	return nil
+}
+
+// checkCanSet returns an error if the updater cannot change the setting s with
+// the given key, because it is frozen in the Values or protected.
+func (u updater) checkCanSet(key string, s extendedSetting) error {
+	if err := u.sv.checkNotFrozen(key); err != nil {
+		return err
+	}
+	return u.checkPrivilege(key, s)
+}
+
+// MakeUpdater implements the Registry interface.
+func (r *registry) MakeUpdater(sv *Values) Updater {
 	return updater{
-		m:  make(map[string]struct{}, len(registry)),
-		sv: sv,
+		r:          r,
+		m:          make(map[string]struct{}, len(r.settings)),
+		sv:         sv,
+		provenance: ProvenanceUpdater,
 	}
 }
 
 // Set attempts to parse and update a setting and notes that it was updated.
+// It behaves as SetWithReason without a reason, so it rejects changes to the
+// settings marked with SetRequireReason unless the Updater was made by
+// NewRefreshUpdater.
 func (u updater) Set(key, rawValue string, vt string) error {
-	d, ok := registry[key]
+	return u.SetWithReason(key, rawValue, vt, "" /* reason */)
+}
+
+// set changes the setting with the given resolved key; see setWithReason. The
+// reason is only used for the change log. The caller must hold writeMu.
+func (u updater) set(key, rawValue, vt, reason string) (err error) {
+	d, ok := u.r.settings[key]
 	if !ok {
 		if _, ok := retiredSettings[key]; ok {
 			return nil
@@ -83,9 +200,23 @@ func (u updater) Set(key, rawValue string, vt string) error {
 
 	u.m[key] = struct{}{}
 
+	// The key is noted as updated even if it is frozen so that
+	// ResetRemaining leaves it alone too.
+	if err := u.checkCanSet(key, d); err != nil {
+		return err
+	}
+
 	if expected := d.Typ(); vt != expected {
 		return errors.Errorf("setting '%s' defined as type %s, not %s", key, expected, vt)
 	}
+	defer u.sv.clearStaleReason(d)
+	if old, ok := changeLogValue(u.sv, d); ok {
+		defer func() {
+			if err == nil {
+				u.sv.logChange(key, old, d.Encoded(u.sv), reason, u.provenance)
+			}
+		}()
+	}
 
 	switch setting := d.(type) {
 	case *StringSetting:
@@ -97,6 +228,12 @@ func (u updater) Set(key, rawValue string, vt string) error {
 		}
 		setting.set(u.sv, b)
 		return nil
+	case *ByteSizeSetting:
+		i, err := ParseByteSize(rawValue)
+		if err != nil {
+			return err
+		}
+		return setting.set(u.sv, i)
 	case numericSetting: // includes *EnumSetting
 		i, err := strconv.Atoi(rawValue)
 		if err != nil {
@@ -110,13 +247,13 @@ func (u updater) Set(key, rawValue string, vt string) error {
 		}
 		return setting.set(u.sv, f)
 	case *DurationSetting:
-		d, err := time.ParseDuration(rawValue)
+		d, err := ParseDuration(rawValue)
 		if err != nil {
 			return err
 		}
 		return setting.set(u.sv, d)
 	case *DurationSettingWithExplicitUnit:
-		d, err := time.ParseDuration(rawValue)
+		d, err := ParseDuration(rawValue)
 		if err != nil {
 			return err
 		}
@@ -127,11 +264,73 @@ func (u updater) Set(key, rawValue string, vt string) error {
 	return nil
 }
 
+// SetWithReason is like Set, but records why the change is being made.
+// Settings marked with SetRequireReason cannot be changed without a reason.
+func (u updater) SetWithReason(key, rawValue, vt, reason string) error {
+	return u.sv.commit(func() error { return u.setWithReason(key, rawValue, vt, reason) })
+}
+
+// setWithReason implements SetWithReason. The caller must hold writeMu.
+func (u updater) setWithReason(key, rawValue, vt, reason string) error {
+	key, rawValue, vt, err := u.r.resolveAlias(key, rawValue, vt)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		reason = u.defaultReason
+	}
+	d, ok := u.r.settings[key]
+	if ok && d.RequiresReason() && reason == "" && !u.refresh {
+		return errors.Errorf("setting '%s' requires a reason", key)
+	}
+	if err := u.set(key, rawValue, vt, reason); err != nil {
+		return err
+	}
+	if ok && reason != "" {
+		u.sv.reasons[d.getSlotIdx()-1].Store(valueWithReason{encoded: d.Encoded(u.sv), reason: reason})
+	}
+	return nil
+}
+
+// ResetPrefix sets all settings whose key starts with prefix to their default
+// values and notes that they were updated, leaving all other settings
+// untouched. Retired settings are hidden from users and are only reset if
+// includeRetired is set. An error is returned if no setting matches, or if an
+// unprivileged updater matches a protected setting, in which case no setting
+// is reset.
+func (u updater) ResetPrefix(prefix string, includeRetired bool) error {
+	return u.sv.commit(func() error { return u.resetPrefix(prefix, includeRetired) })
+}
+
+func (u updater) resetPrefix(prefix string, includeRetired bool) error {
+	var matches []string
+	for k, v := range u.r.settings {
+		if !strings.HasPrefix(k, prefix) || (v.isRetired() && !includeRetired) {
+			continue
+		}
+		if err := u.checkPrivilege(k, v); err != nil {
+			return err
+		}
+		matches = append(matches, k)
+	}
+	if len(matches) == 0 {
+		return errors.Errorf("no settings match prefix '%s'", prefix)
+	}
+	for _, k := range matches {
+		u.m[k] = struct{}{}
+		u.r.settings[k].setToDefault(u.sv)
+	}
+	return nil
+}
+
 // ResetRemaining sets all settings not updated by the updater to their default values.
 func (u updater) ResetRemaining() {
-	for k, v := range registry {
-		if _, ok := u.m[k]; !ok {
-			v.setToDefault(u.sv)
+	_ = u.sv.commit(func() error {
+		for k, v := range u.r.settings {
+			if _, ok := u.m[k]; !ok && u.checkPrivilege(k, v) == nil {
+				v.setToDefault(u.sv)
+			}
 		}
-	}
+		return nil
+	})
 }
@@ -0,0 +1,68 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package settings
+
+import "github.com/cockroachdb/cockroach/pkg/util/syncutil"
+
+// watcher delivers the new values of a setting to the channel returned by one
+// of the typed Watch methods, e.g. IntSetting.Watch.
+type watcher struct {
+	mu     syncutil.Mutex
+	closed bool
+	// send replaces any value still buffered in the channel with the current
+	// value of the setting. It never blocks.
+	send func()
+	// close closes the channel.
+	close func()
+}
+
+func (w *watcher) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.send()
+	}
+}
+
+func (w *watcher) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		w.close()
+	}
+}
+
+// watch registers w to be notified on each change of the setting in the
+// given slot. The returned function unregisters w and closes its channel.
+func (sv *Values) watch(slotIdx int, w *watcher) (cancel func()) {
+	sv.changeMu.Lock()
+	sv.changeMu.watchers[slotIdx-1] = append(sv.changeMu.watchers[slotIdx-1], w)
+	sv.changeMu.Unlock()
+	sv.getRegistry().addSlotCallbacks(slotIdx, 1)
+	return func() {
+		sv.changeMu.Lock()
+		old := sv.changeMu.watchers[slotIdx-1]
+		watchers := make([]*watcher, 0, len(old))
+		for _, o := range old {
+			if o != w {
+				watchers = append(watchers, o)
+			}
+		}
+		sv.changeMu.watchers[slotIdx-1] = watchers
+		sv.changeMu.Unlock()
+		// The watcher may have already been removed, e.g. by unregister.
+		if len(watchers) != len(old) {
+			sv.getRegistry().addSlotCallbacks(slotIdx, -1)
+		}
+		w.stop()
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -36,10 +37,13 @@ var _ planNode = &alterTypeNode{n: nil}
 
 func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, error) {
 	// Resolve the type.
-	desc, err := p.ResolveMutableTypeDescriptor(ctx, n.Type, true /* required */)
+	desc, err := p.ResolveMutableTypeDescriptor(ctx, n.Type, !n.IfExists)
 	if err != nil {
 		return nil, err
 	}
+	if desc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
 
 	// The user needs ownership privilege to alter the type.
 	if err := p.canModifyType(ctx, desc); err != nil {
@@ -65,24 +69,17 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 }
 
 func (n *alterTypeNode) startExec(params runParams) error {
-	telemetry.Inc(n.n.Cmd.TelemetryCounter())
-	var err error
-	switch t := n.n.Cmd.(type) {
-	case *tree.AlterTypeAddValue:
-		err = params.p.addEnumValue(params.ctx, n, t)
-	case *tree.AlterTypeRenameValue:
-		err = params.p.renameTypeValue(params.ctx, n, t.OldVal, t.NewVal)
-	case *tree.AlterTypeRename:
-		err = params.p.renameType(params.ctx, n, t.NewName)
-	case *tree.AlterTypeSetSchema:
-		err = params.p.setTypeSchema(params.ctx, n, t.Schema)
-	case *tree.AlterTypeOwner:
-		err = params.p.alterTypeOwner(params.ctx, n, t.Owner)
-	default:
-		err = errors.AssertionFailedf("unknown alter type cmd %s", t)
+	counters := n.n.TelemetryCounters()
+	for _, c := range counters {
+		telemetry.Inc(c)
 	}
-	if err != nil {
-		return err
+	telemetry.Inc(sqltelemetry.AlterTypeCommandCountCounter(len(counters)))
+
+	// The commands are run in order, as if they were separate statements.
+	for _, cmd := range n.n.Cmds() {
+		if err := n.execCmd(params, cmd); err != nil {
+			return err
+		}
 	}
 
 	// Validate the type descriptor after the changes.
@@ -106,12 +103,113 @@ func (n *alterTypeNode) startExec(params runParams) error {
 	)
 }
 
+// execCmd runs a single command of the ALTER TYPE statement.
+func (n *alterTypeNode) execCmd(params runParams, cmd tree.AlterTypeCmd) error {
+	var err error
+	switch t := cmd.(type) {
+	case *tree.AlterTypeAddValue:
+		err = params.p.addEnumValue(params.ctx, n, t)
+	case *tree.AlterTypeRenameValue:
+		err = params.p.renameTypeValue(params.ctx, n, t.OldVal, t.NewVal)
+	case *tree.AlterTypeRename:
+		err = params.p.renameType(params.ctx, n, t.NewName)
+	case *tree.AlterTypeSetSchema:
+		err = params.p.setTypeSchema(params.ctx, n, t.Schema)
+	case *tree.AlterTypeOwner:
+		err = params.p.alterTypeOwner(params.ctx, n, t.Owner)
+	case *tree.AlterTypeSetOrdering, *tree.AlterTypeSetVisibility, *tree.AlterTypeSetValueSortKey,
+		*tree.AlterTypeSetCaseSensitivity:
+		err = unimplemented.Newf("alter type", "ALTER TYPE%s is not yet supported", tree.AsString(t))
+	case *tree.AlterTypeSetDefault, *tree.AlterTypeDropDefault:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with a DEFAULT is not yet supported")
+	case *tree.AlterTypeConvertUsing:
+		err = unimplemented.Newf("alter type", "ALTER TYPE CONVERT USING is not yet supported")
+	case *tree.AlterTypeConvertToTable:
+		err = unimplemented.Newf("alter type", "ALTER TYPE CONVERT TO TABLE is not yet supported")
+	case *tree.AlterTypeAddConstraint, *tree.AlterTypeDropConstraint:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with a constraint is not yet supported")
+	case *tree.AlterTypeSetWidth:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET WIDTH is not yet supported")
+	case *tree.AlterTypeSetCategory:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET CATEGORY is not yet supported")
+	case *tree.AlterTypeSetStatisticsTarget:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET STATISTICS TARGET is not yet supported")
+	case *tree.AlterTypeSetDisplayFormat:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET DISPLAY FORMAT is not yet supported")
+	case *tree.AlterTypeSetTTL, *tree.AlterTypeDropTTL:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with a TTL is not yet supported")
+	case *tree.AlterTypeSetUnknownPolicy:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET UNKNOWN VALUE POLICY is not yet supported")
+	case *tree.AlterTypeSetFrozen:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET FROZEN is not yet supported")
+	case *tree.AlterTypeSetAuditRetention, *tree.AlterTypeDropAuditRetention:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with an audit retention is not yet supported")
+	case *tree.AlterTypeSetCompression:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET COMPRESSION is not yet supported")
+	case *tree.AlterTypeSetNullsOrder:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET NULLS is not yet supported")
+	case *tree.AlterTypeSetRLS:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with ROW LEVEL SECURITY is not yet supported")
+	case *tree.AlterTypeSetPartitionHint:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET PARTITION HINT is not yet supported")
+	case *tree.AlterTypeSetExternalMapping, *tree.AlterTypeDropExternalMapping:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with an EXTERNAL MAPPING is not yet supported")
+	case *tree.AlterTypeSoftDeleteValue, *tree.AlterTypeRestoreValue:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SOFT DELETE VALUE is not yet supported")
+	case *tree.AlterTypeSetMaxValues:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET MAX VALUES is not yet supported")
+	case *tree.AlterTypeSetLabelsFrom:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET LABELS FROM is not yet supported")
+	case *tree.AlterTypeSetReplicaIdentity:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET REPLICA IDENTITY is not yet supported")
+	case *tree.AlterTypeSetWireFormatVersion:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET WIRE FORMAT VERSION is not yet supported")
+	case *tree.AlterTypeSetValidationWebhook, *tree.AlterTypeDropValidationWebhook:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET VALIDATION WEBHOOK is not yet supported")
+	case *tree.AlterTypeSetValueOption, *tree.AlterTypeResetValueOption:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET VALUE OPTION is not yet supported")
+	case *tree.AlterTypeSetAttributeOrder:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET ATTRIBUTE ORDER is not yet supported")
+	case *tree.AlterTypeSetExtensibility:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET OPEN and SET CLOSED are not yet supported")
+	case *tree.AlterTypeSetUpgradeFunction, *tree.AlterTypeDropUpgradeFunction:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET UPGRADE FUNCTION is not yet supported")
+	case *tree.AlterTypeSetTelemetrySampleRate:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET TELEMETRY SAMPLE RATE is not yet supported")
+	case *tree.AlterTypeSetBinding:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET BINDING is not yet supported")
+	case *tree.AlterTypeSetValueCode:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET VALUE CODE is not yet supported")
+	case *tree.AlterTypeSetDeprecated, *tree.AlterTypeSetNotDeprecated:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET DEPRECATED is not yet supported")
+	case *tree.AlterTypeSetAccessPattern:
+		err = unimplemented.Newf("alter type", "ALTER TYPE SET ACCESS PATTERN is not yet supported")
+	case *tree.AlterTypeAddCast, *tree.AlterTypeDropCast:
+		err = unimplemented.Newf("alter type", "ALTER TYPE ADD CAST and DROP CAST are not yet supported")
+	case *tree.AlterTypeRebuildIndexes:
+		err = unimplemented.Newf("alter type", "ALTER TYPE REBUILD DEPENDENT INDEXES is not yet supported")
+	case *tree.AlterTypeGrant, *tree.AlterTypeRevoke:
+		err = unimplemented.Newf("alter type", "ALTER TYPE with GRANT or REVOKE is not yet supported")
+	case *tree.AlterTypeRenameValues:
+		err = unimplemented.Newf("alter type", "ALTER TYPE RENAME VALUES is not yet supported")
+	default:
+		err = errors.AssertionFailedf("unknown alter type cmd %s", t)
+	}
+	return err
+}
+
 func (p *planner) addEnumValue(
 	ctx context.Context, n *alterTypeNode, node *tree.AlterTypeAddValue,
 ) error {
 	if n.desc.Kind != descpb.TypeDescriptor_ENUM {
 		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", n.desc.Name)
 	}
+	if err := node.Validate(); err != nil {
+		return err
+	}
+	if node.NotValid {
+		return unimplemented.Newf("alter type", "ALTER TYPE ADD VALUE ... NOT VALID is not yet supported")
+	}
 	// See if the value already exists in the enum or not.
 	for _, member := range n.desc.EnumMembers {
 		if member.LogicalRepresentation == node.NewVal {
@@ -1515,6 +1515,160 @@ func TestParse(t *testing.T) {
 		{`ALTER TYPE t RENAME TO t2`},
 		{`ALTER TYPE t SET SCHEMA newschema`},
 		{`ALTER TYPE t OWNER TO foo`},
+		{`ALTER TYPE t SET ORDERING STRICT`},
+		{`ALTER TYPE db.s.t SET ORDERING NONE`},
+		{`ALTER TYPE IF EXISTS t ADD VALUE 'hi'`},
+		{`ALTER TYPE IF EXISTS t ADD VALUE IF NOT EXISTS 'hi'`},
+		{`ALTER TYPE IF EXISTS db.s.t ADD VALUE IF NOT EXISTS 'hi' BEFORE 'hello'`},
+		{`ALTER TYPE IF EXISTS t RENAME VALUE 'value1' TO 'value2'`},
+		{`ALTER TYPE IF EXISTS t RENAME TO t2`},
+		{`ALTER TYPE IF EXISTS t SET SCHEMA newschema`},
+		{`ALTER TYPE IF EXISTS t OWNER TO foo`},
+		{`ALTER TYPE IF EXISTS t SET ORDERING STRICT`},
+		{`ALTER TYPE t SET SCHEMA s, RENAME TO u`},
+		{`ALTER TYPE t RENAME TO u, SET SCHEMA s`},
+		{`ALTER TYPE IF EXISTS db.s.t SET SCHEMA s, RENAME TO u`},
+		{`ALTER TYPE t SET SCHEMA "My Schema", RENAME TO "U"`},
+		{`ALTER TYPE t RENAME TO "my type", SET SCHEMA "S"`},
+		{`ALTER TYPE t RENAME TO "select"`},
+		{`ALTER TYPE t SET DEFAULT 1`},
+		{`ALTER TYPE t SET DEFAULT 'a' || 'b'`},
+		{`ALTER TYPE t SET DEFAULT now()`},
+		{`ALTER TYPE t SET DEFAULT (1 + 2) * 3`},
+		{`ALTER TYPE t SET DEFAULT NULL`},
+		{`ALTER TYPE t DROP DEFAULT`},
+		{`ALTER TYPE IF EXISTS t DROP DEFAULT, SET DEFAULT 1`},
+		{`ALTER TYPE t SET VISIBILITY HIDDEN`},
+		{`ALTER TYPE db.s.t SET VISIBILITY VISIBLE`},
+		{`ALTER TYPE t SET VALUE 'x' SORT KEY 42`},
+		{`ALTER TYPE t SET VALUE e'it\'s' SORT KEY -1`},
+		{`ALTER TYPE t ADD VALUE 'x' NOT VALID`},
+		{`ALTER TYPE t ADD VALUE 'x' BEFORE 'y' NOT VALID`},
+		{`ALTER TYPE t ADD VALUE IF NOT EXISTS 'x' AFTER 'y' NOT VALID`},
+		{`ALTER TYPE IF EXISTS t ADD VALUE IF NOT EXISTS 'x' NOT VALID, RENAME TO u`},
+		{`ALTER TYPE t ADD CONSTRAINT c CHECK (value > 0)`},
+		{`ALTER TYPE t ADD CHECK (length(value) < 10)`},
+		{`ALTER TYPE t ADD CONSTRAINT IF NOT EXISTS c CHECK (value > 0)`},
+		{`ALTER TYPE t ADD CONSTRAINT IF NOT EXISTS c CHECK (value > 0), ADD CONSTRAINT d CHECK (value < 10)`},
+		{`ALTER TYPE t DROP CONSTRAINT c`},
+		{`ALTER TYPE t DROP CONSTRAINT c CASCADE`},
+		{`ALTER TYPE t DROP CONSTRAINT c RESTRICT, ADD CONSTRAINT d CHECK (value IN ('a', 'b'))`},
+		{`ALTER TYPE t CONVERT USING 1`},
+		{`ALTER TYPE t CONVERT USING lower(x)`},
+		{`ALTER TYPE t CONVERT USING CASE WHEN x = 'a' THEN 'b' ELSE x END`},
+		{`ALTER TYPE t CONVERT USING x::STRING || 'suffix'`},
+		{`ALTER TYPE t CONVERT TO TABLE u`},
+		{`ALTER TYPE t CONVERT TO TABLE s.u`},
+		{`ALTER TYPE t CONVERT TO TABLE db.s.u`},
+		{`ALTER TYPE t CONVERT TO TABLE "my schema"."my table"`},
+		{`ALTER TYPE t RENAME VALUES ('a' TO 'b')`},
+		{`ALTER TYPE t RENAME VALUES ('a' TO 'b', 'c' TO 'd')`},
+		{`ALTER TYPE t RENAME VALUES ('a' TO 'b', 'b' TO 'a')`},
+		{`ALTER TYPE t SET WIDTH 4`},
+		{`ALTER TYPE t SET WIDTH 8, RENAME TO u`},
+		{`ALTER TYPE t SET CATEGORY 'E'`},
+		{`ALTER TYPE t SET CATEGORY 'U', RENAME TO u`},
+		{`ALTER TYPE t SET STATISTICS TARGET 100`},
+		{`ALTER TYPE t SET STATISTICS TARGET 0, RENAME TO u`},
+		{`ALTER TYPE t SET CASE SENSITIVE`},
+		{`ALTER TYPE t SET CASE INSENSITIVE`},
+		{`ALTER TYPE t SET CASE INSENSITIVE, RENAME TO u`},
+		{`ALTER TYPE t SET DISPLAY FORMAT 'title_case'`},
+		{`ALTER TYPE t SET DISPLAY FORMAT 'Title Case', RENAME TO u`},
+		{`ALTER TYPE t GRANT USAGE TO foo`},
+		{`ALTER TYPE t GRANT USAGE, GRANT TO foo`},
+		{`ALTER TYPE t GRANT USAGE TO foo, GRANT USAGE TO bar`},
+		{`ALTER TYPE t GRANT USAGE TO "Foo Bar"`},
+		{`ALTER TYPE t GRANT ALL TO foo`},
+		{`ALTER TYPE t REVOKE USAGE FROM foo`},
+		{`ALTER TYPE t REVOKE ALL FROM foo`},
+		{`ALTER TYPE t GRANT USAGE TO foo, REVOKE USAGE FROM bar`},
+		{`ALTER TYPE t GRANT USAGE TO (foo, bar)`},
+		{`ALTER TYPE t GRANT USAGE, GRANT TO (foo, "Bar Baz"), RENAME TO u`},
+		{`ALTER TYPE t REVOKE ALL FROM (foo, bar), GRANT USAGE TO baz`},
+		{`ALTER TYPE t SET TTL '1h0m0s'`},
+		{`ALTER TYPE t SET TTL '1h30m0s', RENAME TO u`},
+		{`ALTER TYPE t DROP TTL`},
+		{`ALTER TYPE t SET TTL '5m0s', DROP TTL`},
+		{`ALTER TYPE t REBUILD DEPENDENT INDEXES`},
+		{`ALTER TYPE t SET ORDERING STRICT, REBUILD DEPENDENT INDEXES`},
+		{`ALTER TYPE t SET UNKNOWN VALUE POLICY ERROR`},
+		{`ALTER TYPE t SET UNKNOWN VALUE POLICY FALLBACK 'x'`},
+		{`ALTER TYPE t SET UNKNOWN VALUE POLICY FALLBACK 'x', RENAME TO u`},
+		{`ALTER TYPE t SET FROZEN`},
+		{`ALTER TYPE t SET UNFROZEN`},
+		{`ALTER TYPE t SET UNFROZEN, ADD VALUE 'x', SET FROZEN`},
+		{`ALTER TYPE t SET AUDIT RETENTION '30 days'`},
+		{`ALTER TYPE t SET AUDIT RETENTION '1 year 2 mons'`},
+		{`ALTER TYPE t DROP AUDIT RETENTION`},
+		{`ALTER TYPE t SET AUDIT RETENTION '30 days', DROP AUDIT RETENTION`},
+		{`ALTER TYPE t SET COMPRESSION lz4`},
+		{`ALTER TYPE t SET COMPRESSION none`},
+		{`ALTER TYPE t SET COMPRESSION lz4, RENAME TO u`},
+		{`ALTER TYPE t SET NULLS FIRST`},
+		{`ALTER TYPE t SET NULLS LAST`},
+		{`ALTER TYPE t SET NULLS LAST, RENAME TO u`},
+		{`ALTER TYPE t ENABLE ROW LEVEL SECURITY`},
+		{`ALTER TYPE t DISABLE ROW LEVEL SECURITY`},
+		{`ALTER TYPE t DISABLE ROW LEVEL SECURITY, RENAME TO u`},
+		{`ALTER TYPE t SET PARTITION HINT 'region'`},
+		{`ALTER TYPE t SET PARTITION HINT 'region', RENAME TO u`},
+		{`ALTER TYPE t SET EXTERNAL MAPPING 'x' => '100'`},
+		{`ALTER TYPE t DROP EXTERNAL MAPPING 'x'`},
+		{`ALTER TYPE t SET EXTERNAL MAPPING 'x' => '1', DROP EXTERNAL MAPPING 'y'`},
+		{`ALTER TYPE t SOFT DELETE VALUE 'x'`},
+		{`ALTER TYPE t RESTORE VALUE 'x'`},
+		{`ALTER TYPE t SOFT DELETE VALUE 'x', RESTORE VALUE 'y'`},
+		{`ALTER TYPE t SET MAX VALUES 256`},
+		{`ALTER TYPE t SET MAX VALUES 1, RENAME TO u`},
+		{`ALTER TYPE t SET LABELS FROM labels`},
+		{`ALTER TYPE t SET LABELS FROM s.labels`},
+		{`ALTER TYPE t SET LABELS FROM db.s.labels`},
+		{`ALTER TYPE t SET LABELS FROM "My Schema"."Labels", RENAME TO u`},
+		{`ALTER TYPE t SET REPLICA IDENTITY DEFAULT`},
+		{`ALTER TYPE t SET REPLICA IDENTITY FULL`},
+		{`ALTER TYPE t SET REPLICA IDENTITY FULL, SET SCHEMA s`},
+		{`ALTER TYPE t SET WIRE FORMAT VERSION 2`},
+		{`ALTER TYPE t SET WIRE FORMAT VERSION 1, RENAME TO u`},
+		{`ALTER TYPE t SET VALIDATION WEBHOOK 'https://example.com/check'`},
+		{`ALTER TYPE t DROP VALIDATION WEBHOOK`},
+		{`ALTER TYPE t DROP VALIDATION WEBHOOK, SET VALIDATION WEBHOOK 'https://example.com:8443/v2?t=1'`},
+		{`ALTER TYPE t SET VALUE 'x' OPTION color = 'red'`},
+		{`ALTER TYPE t SET VALUE 'x' OPTION "My Key" = 'red'`},
+		{`ALTER TYPE t RESET VALUE 'x' OPTION color`},
+		{`ALTER TYPE t SET VALUE 'x' OPTION color = 'red', RESET VALUE 'y' OPTION color`},
+		{`ALTER TYPE t SET ATTRIBUTE ORDER (a)`},
+		{`ALTER TYPE t SET ATTRIBUTE ORDER (a, b, c)`},
+		{`ALTER TYPE t SET ATTRIBUTE ORDER ("My Attr", b), RENAME TO u`},
+		{`ALTER TYPE t SET OPEN`},
+		{`ALTER TYPE t SET CLOSED`},
+		{`ALTER TYPE t SET CLOSED, ADD VALUE 'x'`},
+		{`ALTER TYPE t SET UPGRADE FUNCTION f`},
+		{`ALTER TYPE t SET UPGRADE FUNCTION s.f`},
+		{`ALTER TYPE t SET UPGRADE FUNCTION db.s."My Func"`},
+		{`ALTER TYPE t DROP UPGRADE FUNCTION`},
+		{`ALTER TYPE t DROP UPGRADE FUNCTION, SET UPGRADE FUNCTION f`},
+		{`ALTER TYPE t SET TELEMETRY SAMPLE RATE 0.1`},
+		{`ALTER TYPE t SET TELEMETRY SAMPLE RATE 0`},
+		{`ALTER TYPE t SET TELEMETRY SAMPLE RATE 1`},
+		{`ALTER TYPE t SET TELEMETRY SAMPLE RATE 0.00001`},
+		{`ALTER TYPE t SET BINDING EARLY`},
+		{`ALTER TYPE t SET BINDING LATE`},
+		{`ALTER TYPE t SET BINDING LATE, SET SCHEMA s`},
+		{`ALTER TYPE t SET VALUE 'x' CODE 7`},
+		{`ALTER TYPE t SET VALUE 'x' CODE 0, SET VALUE 'y' CODE 1`},
+		{`ALTER TYPE t SET DEPRECATED`},
+		{`ALTER TYPE t SET DEPRECATED 'use othertype'`},
+		{`ALTER TYPE t SET NOT DEPRECATED`},
+		{`ALTER TYPE t SET DEPRECATED, RENAME TO u`},
+		{`ALTER TYPE t SET ACCESS PATTERN SEQUENTIAL`},
+		{`ALTER TYPE t SET ACCESS PATTERN RANDOM`},
+		{`ALTER TYPE t SET ACCESS PATTERN RANDOM, SET CLOSED`},
+		{`ALTER TYPE t ADD CAST TO INT8 WITH FUNCTION f`},
+		{`ALTER TYPE t ADD CAST TO s.u WITH FUNCTION db.s.f AS ASSIGNMENT`},
+		{`ALTER TYPE t ADD CAST TO STRING[] WITH FUNCTION f AS IMPLICIT`},
+		{`ALTER TYPE t DROP CAST TO INT8`},
+		{`ALTER TYPE t DROP CAST TO s.u, ADD CAST TO s.u WITH FUNCTION g AS IMPLICIT`},
 
 		{`REASSIGN OWNED BY foo TO bar`},
 		{`REASSIGN OWNED BY foo, bar TO third`},
@@ -2609,6 +2763,38 @@ SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS,
 
 		{`ALTER TYPE t OWNER TO CURRENT_USER`, `ALTER TYPE t OWNER TO "current_user"`},
 		{`ALTER TYPE t OWNER TO SESSION_USER`, `ALTER TYPE t OWNER TO "session_user"`},
+		{`ALTER TYPE t GRANT USAGE TO (foo)`, `ALTER TYPE t GRANT USAGE TO foo`},
+		{`ALTER TYPE t GRANT USAGE TO CURRENT_USER`, `ALTER TYPE t GRANT USAGE TO "current_user"`},
+		{`ALTER TYPE t REVOKE USAGE FROM (SESSION_USER, 'foo')`, `ALTER TYPE t REVOKE USAGE FROM ("session_user", foo)`},
+		{`ALTER TYPE t RENAME VALUES ('it''s' TO 'b')`, `ALTER TYPE t RENAME VALUES (e'it\'s' TO 'b')`},
+		{`ALTER TYPE t SET CATEGORY ''''`, `ALTER TYPE t SET CATEGORY e'\''`},
+		{`ALTER TYPE t SET DISPLAY FORMAT 'it''s'`, `ALTER TYPE t SET DISPLAY FORMAT e'it\'s'`},
+		{`ALTER TYPE t SET TTL '1h'`, `ALTER TYPE t SET TTL '1h0m0s'`},
+		{`ALTER TYPE t SET TTL '90s'`, `ALTER TYPE t SET TTL '1m30s'`},
+		{`ALTER TYPE t SET UNKNOWN VALUE POLICY error`, `ALTER TYPE t SET UNKNOWN VALUE POLICY ERROR`},
+		{`ALTER TYPE t SET AUDIT RETENTION '720h'`, `ALTER TYPE t SET AUDIT RETENTION '720:00:00'`},
+		{`ALTER TYPE t SET COMPRESSION LZ4`, `ALTER TYPE t SET COMPRESSION lz4`},
+		{`ALTER TYPE t SET COMPRESSION NONE`, `ALTER TYPE t SET COMPRESSION none`},
+		{`ALTER TYPE t SET PARTITION HINT 'it''s'`, `ALTER TYPE t SET PARTITION HINT e'it\'s'`},
+		{`ALTER TYPE t SET EXTERNAL MAPPING 'x'=>'100'`, `ALTER TYPE t SET EXTERNAL MAPPING 'x' => '100'`},
+		{`ALTER TYPE t SET EXTERNAL MAPPING 'it''s' => 'a''b'`, `ALTER TYPE t SET EXTERNAL MAPPING e'it\'s' => e'a\'b'`},
+		{`ALTER TYPE t SOFT DELETE VALUE 'it''s'`, `ALTER TYPE t SOFT DELETE VALUE e'it\'s'`},
+		{`ALTER TYPE t RESTORE VALUE 'it''s'`, `ALTER TYPE t RESTORE VALUE e'it\'s'`},
+		{`ALTER TYPE t SET VALIDATION WEBHOOK 'https://example.com/it''s'`, `ALTER TYPE t SET VALIDATION WEBHOOK e'https://example.com/it\'s'`},
+		{`ALTER TYPE t SET VALUE 'it''s' OPTION k = 'it''s'`, `ALTER TYPE t SET VALUE e'it\'s' OPTION k = e'it\'s'`},
+		{`ALTER TYPE t RESET VALUE 'it''s' OPTION k`, `ALTER TYPE t RESET VALUE e'it\'s' OPTION k`},
+		{`ALTER TYPE t SET TELEMETRY SAMPLE RATE 1e-3`, `ALTER TYPE t SET TELEMETRY SAMPLE RATE 0.001`},
+		{`ALTER TYPE t SET TELEMETRY SAMPLE RATE 1.0`, `ALTER TYPE t SET TELEMETRY SAMPLE RATE 1`},
+		{`ALTER TYPE t SET BINDING early`, `ALTER TYPE t SET BINDING EARLY`},
+		{`ALTER TYPE t SET BINDING "Late"`, `ALTER TYPE t SET BINDING LATE`},
+		{`ALTER TYPE t SET VALUE 'it''s' CODE 7`, `ALTER TYPE t SET VALUE e'it\'s' CODE 7`},
+		{`ALTER TYPE t SET DEPRECATED 'it''s old'`, `ALTER TYPE t SET DEPRECATED e'it\'s old'`},
+		{`ALTER TYPE t SET DEPRECATED ''`, `ALTER TYPE t SET DEPRECATED`},
+		{`ALTER TYPE t SET ACCESS PATTERN sequential`, `ALTER TYPE t SET ACCESS PATTERN SEQUENTIAL`},
+		{`ALTER TYPE t SET ACCESS PATTERN "Random"`, `ALTER TYPE t SET ACCESS PATTERN RANDOM`},
+		{`ALTER TYPE t ADD CAST TO int WITH FUNCTION f`, `ALTER TYPE t ADD CAST TO INT8 WITH FUNCTION f`},
+		{`ALTER TYPE t SET AUDIT RETENTION '1 day 1 hour'`, `ALTER TYPE t SET AUDIT RETENTION '1 day 01:00:00'`},
+		{`ALTER TYPE t SET UNKNOWN VALUE POLICY fallback 'it''s'`, `ALTER TYPE t SET UNKNOWN VALUE POLICY FALLBACK e'it\'s'`},
 
 		{`REASSIGN OWNED BY CURRENT_USER TO foo`, `REASSIGN OWNED BY "current_user" TO foo`},
 		{`REASSIGN OWNED BY SESSION_USER TO foo`, `REASSIGN OWNED BY "session_user" TO foo`},
@@ -2666,6 +2852,43 @@ func TestParseTree(t *testing.T) {
 	}
 }
 
+// TestParseAlterTypeGuards verifies that the statement-level IF EXISTS and
+// the ADD VALUE IF NOT EXISTS guards of ALTER TYPE compose when formatted, and
+// that the result parses back to the same syntax tree.
+func TestParseAlterTypeGuards(t *testing.T) {
+	for _, ifExists := range []bool{false, true} {
+		for _, ifNotExists := range []bool{false, true} {
+			name, err := tree.NewUnresolvedObjectName(1, [3]string{"t"}, 1 /* annotationIdx */)
+			require.NoError(t, err)
+			stmt := &tree.AlterType{
+				Type:     name,
+				IfExists: ifExists,
+				Cmd:      &tree.AlterTypeAddValue{NewVal: "x", IfNotExists: ifNotExists},
+			}
+			sql := tree.AsString(stmt)
+			t.Run(sql, func(t *testing.T) {
+				reparsed, err := parser.ParseOne(sql)
+				require.NoError(t, err)
+				require.Equal(t, stmt, reparsed.AST)
+			})
+		}
+	}
+}
+
+// TestParseAlterTypeMultipleCommands verifies that the commands of an ALTER
+// TYPE statement with several commands are kept in order.
+func TestParseAlterTypeMultipleCommands(t *testing.T) {
+	stmt, err := parser.ParseOne(`ALTER TYPE t SET SCHEMA s,RENAME TO u`)
+	require.NoError(t, err)
+	alter, ok := stmt.AST.(*tree.AlterType)
+	require.True(t, ok)
+	require.Equal(t, &tree.AlterTypeCmds{
+		&tree.AlterTypeSetSchema{Schema: "s"},
+		&tree.AlterTypeRename{NewName: "u"},
+	}, alter.Cmd)
+	require.Equal(t, `ALTER TYPE t SET SCHEMA s, RENAME TO u`, tree.AsString(alter))
+}
+
 // TestParseSyntax verifies that parsing succeeds, though the syntax tree
 // likely differs. All of the test cases here should eventually be moved
 // elsewhere.
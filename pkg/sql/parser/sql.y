@@ -540,9 +540,15 @@ func (u *sqlSymUnion) typeReference() tree.ResolvableTypeReference {
 func (u *sqlSymUnion) typeReferences() []tree.ResolvableTypeReference {
     return u.val.([]tree.ResolvableTypeReference)
 }
+func (u *sqlSymUnion) alterTypeCmd() tree.AlterTypeCmd {
+    return u.val.(tree.AlterTypeCmd)
+}
 func (u *sqlSymUnion) alterTypeAddValuePlacement() *tree.AlterTypeAddValuePlacement {
     return u.val.(*tree.AlterTypeAddValuePlacement)
 }
+func (u *sqlSymUnion) alterTypeRenameValuePairs() []tree.AlterTypeRenameValuePair {
+    return u.val.([]tree.AlterTypeRenameValuePair)
+}
 func (u *sqlSymUnion) scheduleState() tree.ScheduleState {
   return u.val.(tree.ScheduleState)
 }
@@ -573,16 +579,16 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 // Ordinary key words in alphabetical order.
 %token <str> ABORT ACCESS ACTION ADD ADMIN AFTER AGGREGATE
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
-%token <str> ASYMMETRIC AT ATTRIBUTE AUTHORIZATION AUTOMATIC
+%token <str> ASSIGNMENT ASYMMETRIC AT ATTRIBUTE AUDIT AUTHORIZATION AUTOMATIC
 
-%token <str> BACKUP BACKUPS BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
+%token <str> BACKUP BACKUPS BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BINDING BIT
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
-%token <str> CHARACTER CHARACTERISTICS CHECK CLOSE
-%token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
-%token <str> COMMITTED COMPACT COMPLETE CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
+%token <str> CACHE CANCEL CANCELQUERY CASCADE CASE CAST CATEGORY CBRT CHANGEFEED CHAR
+%token <str> CHARACTER CHARACTERISTICS CHECK CLOSE CLOSED
+%token <str> CLUSTER COALESCE CODE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
+%token <str> COMMITTED COMPACT COMPLETE COMPRESSION CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
 %token <str> CONFLICT CONNECTION CONSTRAINT CONSTRAINTS CONTAINS CONTROLCHANGEFEED CONTROLJOB
 %token <str> CONVERSION CONVERT COPY COVERING CREATE CREATEDB CREATELOGIN CREATEROLE
 %token <str> CROSS CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
@@ -590,42 +596,42 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %token <str> CURRENT_USER CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT DEFAULTS
-%token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DESC DESTINATION DETACHED
-%token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
+%token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DEPENDENT DEPRECATED DESC DESTINATION DETACHED
+%token <str> DISABLE DISCARD DISPLAY DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> ELSE ENABLE ENCODING ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT
-%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION
+%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTERNAL EXTRACT EXTRACT_DURATION
 
 %token <str> FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
-%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL FUNCTION
+%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FORMAT FROM FROZEN FULL FUNCTION
 
 %token <str> GENERATED GEOGRAPHY GEOMETRY GEOMETRYM GEOMETRYZ GEOMETRYZM
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
 %token <str> GLOBAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
 
-%token <str> HAVING HASH HIGH HISTOGRAM HOUR
+%token <str> HAVING HASH HIDDEN HIGH HINT HISTOGRAM HOUR
 
 %token <str> IDENTITY
-%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
+%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPLICIT IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INTERLEAVE INITIALLY
-%token <str> INNER INSERT INT INTEGER
+%token <str> INNER INSENSITIVE INSERT INT INTEGER
 %token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED IS ISERROR ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
 
 %token <str> KEY KEYS KMS KV
 
-%token <str> LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
+%token <str> LABELS LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
 %token <str> LIST LOCAL LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
+%token <str> MAPPING MATCH MATERIALIZED MAX MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
@@ -634,42 +640,43 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %token <str> NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING NO_INDEX_JOIN
 %token <str> NONE NORMAL NOT NOTHING NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
 
-%token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPT OPTION OPTIONS OR
-%token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OWNER OPERATOR
+%token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPEN OPT OPTION OPTIONS OR
+%token <str> ORDER ORDERING ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OWNER OPERATOR
 
-%token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACING
-%token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
+%token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PATTERN PAUSE PAUSED PHYSICAL PLACING
+%token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLICY POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIORITY
 %token <str> PROCEDURAL PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
+%token <str> RANGE RANGES RATE READ REAL REASSIGN REBUILD RECURSIVE RECURRING REF REFERENCES REFRESH
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE REINDEX
-%token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETRY REVISION_HISTORY REVOKE RIGHT
+%token <str> REMOVE_PATH RENAME REPEATABLE REPLACE REPLICA
+%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETENTION RETURNING RETRY REVISION_HISTORY REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE RUNNING
 
-%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAMPLE SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SECURITY SELECT SENSITIVE SEQUENCE SEQUENCES
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_MISSING_FOREIGN_KEYS
-%token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
+%token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOFT SOME SORT SPLIT SQL
 
 %token <str> START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION
 
-%token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
+%token <str> TABLE TABLES TABLESPACE TARGET TELEMETRY TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
 %token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE
 %token <str> TRANSACTION TRANSACTIONS TREAT TRIGGER TRIM TRUE
-%token <str> TRUNCATE TRUSTED TYPE TYPES
+%token <str> TRUNCATE TRUSTED TTL TYPE TYPES
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
-%token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
+%token <str> UNBOUNDED UNCOMMITTED UNFROZEN UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UPDATE UPGRADE UPSERT UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL
+%token <str> VALID VALIDATE VALIDATION VALUE VALUES VARBIT VARCHAR VARIADIC VERSION VIEW VARYING VIEWACTIVITY VIRTUAL
+%token <str> VISIBILITY VISIBLE
 
-%token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
+%token <str> WEBHOOK WHEN WHERE WIDTH WINDOW WIRE WITH WITHIN WITHOUT WORK WRITE
 
 %token <str> YEAR
 
@@ -1077,7 +1084,10 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 
 %type <tree.ResolvableTypeReference> typename simple_typename cast_target
 %type <*types.T> const_typename
+%type <tree.AlterTypeCmd> alter_type_cmd
+%type <tree.AlterTypeCmd> alter_type_cmds
 %type <*tree.AlterTypeAddValuePlacement> opt_add_val_placement
+%type <[]tree.AlterTypeRenameValuePair> rename_value_list
 %type <bool> opt_timezone
 %type <*types.T> numeric opt_numeric_modifiers
 %type <*types.T> opt_float
@@ -1103,6 +1113,7 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <str> non_reserved_word_or_sconst
 %type <str> role_spec
 %type <[]string> role_spec_list
+%type <tree.NameList> alter_type_grantees
 %type <tree.Expr> zone_value
 %type <tree.Expr> string_or_placeholder
 %type <tree.Expr> string_or_placeholder_list
@@ -1988,14 +1999,66 @@ opt_validate_behavior:
 
 // %Help: ALTER TYPE - change the definition of a type.
 // %Category: DDL
-// %Text: ALTER TYPE <typename> <command>
+// %Text: ALTER TYPE [IF EXISTS] <typename> <command> [, ...]
 //
 // Commands:
-//   ALTER TYPE ... ADD VALUE [IF NOT EXISTS] <value> [ { BEFORE | AFTER } <value> ]
+//   ALTER TYPE ... ADD VALUE [IF NOT EXISTS] <value> [ { BEFORE | AFTER } <value> ] [NOT VALID]
 //   ALTER TYPE ... RENAME VALUE <oldname> TO <newname>
+//   ALTER TYPE ... RENAME VALUES (<oldname> TO <newname> [, ...])
 //   ALTER TYPE ... RENAME TO <newname>
 //   ALTER TYPE ... SET SCHEMA <newschemaname>
 //   ALTER TYPE ... OWNER TO {<newowner> | CURRENT_USER | SESSION_USER }
+//   ALTER TYPE ... SET ORDERING { STRICT | NONE }
+//   ALTER TYPE ... SET DEFAULT <expr>
+//   ALTER TYPE ... DROP DEFAULT
+//   ALTER TYPE ... SET VISIBILITY { HIDDEN | VISIBLE }
+//   ALTER TYPE ... SET VALUE <value> SORT KEY <sortkey>
+//   ALTER TYPE ... SET WIDTH <width>
+//   ALTER TYPE ... SET CATEGORY <category>
+//   ALTER TYPE ... SET STATISTICS TARGET <target>
+//   ALTER TYPE ... SET CASE { SENSITIVE | INSENSITIVE }
+//   ALTER TYPE ... SET DISPLAY FORMAT <format>
+//   ALTER TYPE ... SET TTL <duration>
+//   ALTER TYPE ... DROP TTL
+//   ALTER TYPE ... SET UNKNOWN VALUE POLICY { ERROR | FALLBACK <value> }
+//   ALTER TYPE ... SET { FROZEN | UNFROZEN }
+//   ALTER TYPE ... SET AUDIT RETENTION <interval>
+//   ALTER TYPE ... DROP AUDIT RETENTION
+//   ALTER TYPE ... SET COMPRESSION { LZ4 | NONE }
+//   ALTER TYPE ... SET NULLS { FIRST | LAST }
+//   ALTER TYPE ... { ENABLE | DISABLE } ROW LEVEL SECURITY
+//   ALTER TYPE ... SET PARTITION HINT <hint>
+//   ALTER TYPE ... SET EXTERNAL MAPPING <value> => <code>
+//   ALTER TYPE ... DROP EXTERNAL MAPPING <value>
+//   ALTER TYPE ... SOFT DELETE VALUE <value>
+//   ALTER TYPE ... RESTORE VALUE <value>
+//   ALTER TYPE ... SET MAX VALUES <count>
+//   ALTER TYPE ... SET LABELS FROM <tablename>
+//   ALTER TYPE ... SET REPLICA IDENTITY { DEFAULT | FULL }
+//   ALTER TYPE ... SET WIRE FORMAT VERSION <version>
+//   ALTER TYPE ... SET VALIDATION WEBHOOK <url>
+//   ALTER TYPE ... DROP VALIDATION WEBHOOK
+//   ALTER TYPE ... SET VALUE <value> OPTION <key> = <value>
+//   ALTER TYPE ... RESET VALUE <value> OPTION <key>
+//   ALTER TYPE ... SET ATTRIBUTE ORDER (<attributename> [, ...])
+//   ALTER TYPE ... SET { OPEN | CLOSED }
+//   ALTER TYPE ... SET UPGRADE FUNCTION <funcname>
+//   ALTER TYPE ... DROP UPGRADE FUNCTION
+//   ALTER TYPE ... SET TELEMETRY SAMPLE RATE <rate>
+//   ALTER TYPE ... SET BINDING { EARLY | LATE }
+//   ALTER TYPE ... SET VALUE <value> CODE <code>
+//   ALTER TYPE ... SET DEPRECATED [<message>]
+//   ALTER TYPE ... SET NOT DEPRECATED
+//   ALTER TYPE ... SET ACCESS PATTERN { SEQUENTIAL | RANDOM }
+//   ALTER TYPE ... ADD CAST TO <type> WITH FUNCTION <funcname> [AS IMPLICIT | AS ASSIGNMENT]
+//   ALTER TYPE ... DROP CAST TO <type>
+//   ALTER TYPE ... REBUILD DEPENDENT INDEXES
+//   ALTER TYPE ... GRANT {ALL | <privileges...> } TO { <grantee> | (<grantee> [, ...]) }
+//   ALTER TYPE ... REVOKE {ALL | <privileges...> } FROM { <grantee> | (<grantee> [, ...]) }
+//   ALTER TYPE ... CONVERT USING <expr>
+//   ALTER TYPE ... CONVERT TO TABLE <tablename>
+//   ALTER TYPE ... ADD [CONSTRAINT [IF NOT EXISTS] <constraintname>] CHECK (<expr>)
+//   ALTER TYPE ... DROP CONSTRAINT <constraintname> [RESTRICT | CASCADE]
 //   ALTER TYPE ... RENAME ATTRIBUTE <oldname> TO <newname> [ CASCADE | RESTRICT ]
 //   ALTER TYPE ... <attributeaction> [, ... ]
 //
@@ -2006,74 +2069,529 @@ opt_validate_behavior:
 //
 // %SeeAlso: WEBDOCS/alter-type.html
 alter_type_stmt:
-  ALTER TYPE type_name ADD VALUE SCONST opt_add_val_placement
+  ALTER TYPE type_name alter_type_cmds
   {
     $$.val = &tree.AlterType{
       Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeAddValue{
-        NewVal: $6,
-        IfNotExists: false,
-        Placement: $7.alterTypeAddValuePlacement(),
-      },
+      Cmd: $4.alterTypeCmd(),
     }
   }
-| ALTER TYPE type_name ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement
+| ALTER TYPE IF EXISTS type_name alter_type_cmds
   {
     $$.val = &tree.AlterType{
-      Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeAddValue{
-        NewVal: $9,
-        IfNotExists: true,
-        Placement: $10.alterTypeAddValuePlacement(),
-      },
+      Type: $5.unresolvedObjectName(),
+      IfExists: true,
+      Cmd: $6.alterTypeCmd(),
     }
   }
-| ALTER TYPE type_name RENAME VALUE SCONST TO SCONST
+| ALTER TYPE type_name RENAME ATTRIBUTE column_name TO column_name opt_drop_behavior
   {
-    $$.val = &tree.AlterType{
-      Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeRenameValue{
-        OldVal: $6,
-        NewVal: $8,
-      },
+    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+  }
+| ALTER TYPE type_name alter_attribute_action_list
+  {
+    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+  }
+| ALTER TYPE error // SHOW HELP: ALTER TYPE
+
+// A single command is used as is; only statements with several commands are
+// wrapped in an AlterTypeCmds list.
+alter_type_cmds:
+  alter_type_cmd
+  {
+    $$.val = $1.alterTypeCmd()
+  }
+| alter_type_cmds ',' alter_type_cmd
+  {
+    cmds, ok := $1.alterTypeCmd().(*tree.AlterTypeCmds)
+    if !ok {
+      cmds = &tree.AlterTypeCmds{$1.alterTypeCmd()}
     }
+    *cmds = append(*cmds, $3.alterTypeCmd())
+    $$.val = cmds
   }
-| ALTER TYPE type_name RENAME TO name
+
+alter_type_cmd:
+  ADD VALUE SCONST opt_add_val_placement opt_validate_behavior
   {
-    $$.val = &tree.AlterType{
-      Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeRename{
-        NewName: $6,
-      },
+    $$.val = &tree.AlterTypeAddValue{
+      NewVal: $3,
+      IfNotExists: false,
+      Placement: $4.alterTypeAddValuePlacement(),
+      NotValid: $5.validationBehavior() == tree.ValidationSkip,
     }
   }
-| ALTER TYPE type_name SET SCHEMA schema_name
+| ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement opt_validate_behavior
   {
-    $$.val = &tree.AlterType{
-      Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeSetSchema{
-        Schema: $6,
-      },
+    $$.val = &tree.AlterTypeAddValue{
+      NewVal: $6,
+      IfNotExists: true,
+      Placement: $7.alterTypeAddValuePlacement(),
+      NotValid: $8.validationBehavior() == tree.ValidationSkip,
     }
   }
-| ALTER TYPE type_name OWNER TO role_spec
+| RENAME VALUE SCONST TO SCONST
   {
-    $$.val = &tree.AlterType{
-      Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeOwner{
-        Owner: $6,
-      },
+    $$.val = &tree.AlterTypeRenameValue{
+      OldVal: $3,
+      NewVal: $5,
     }
   }
-| ALTER TYPE type_name RENAME ATTRIBUTE column_name TO column_name opt_drop_behavior
+| RENAME VALUES '(' rename_value_list ')'
   {
-    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+    cmd, err := tree.NewAlterTypeRenameValues($4.alterTypeRenameValuePairs())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
   }
-| ALTER TYPE type_name alter_attribute_action_list
+| RENAME TO name
   {
-    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+    $$.val = &tree.AlterTypeRename{
+      NewName: $3,
+    }
+  }
+| SET SCHEMA schema_name
+  {
+    $$.val = &tree.AlterTypeSetSchema{
+      Schema: $3,
+    }
+  }
+| OWNER TO role_spec
+  {
+    $$.val = &tree.AlterTypeOwner{
+      Owner: $3,
+    }
+  }
+| SET ORDERING STRICT
+  {
+    $$.val = &tree.AlterTypeSetOrdering{
+      Ordering: tree.AlterTypeOrderingStrict,
+    }
+  }
+| SET ORDERING NONE
+  {
+    $$.val = &tree.AlterTypeSetOrdering{
+      Ordering: tree.AlterTypeOrderingNone,
+    }
+  }
+| SET DEFAULT a_expr
+  {
+    $$.val = &tree.AlterTypeSetDefault{
+      Default: $3.expr(),
+    }
+  }
+| DROP DEFAULT
+  {
+    $$.val = &tree.AlterTypeDropDefault{}
+  }
+| SET VISIBILITY HIDDEN
+  {
+    $$.val = &tree.AlterTypeSetVisibility{
+      Visibility: tree.AlterTypeVisibilityHidden,
+    }
+  }
+| SET VISIBILITY VISIBLE
+  {
+    $$.val = &tree.AlterTypeSetVisibility{
+      Visibility: tree.AlterTypeVisibilityVisible,
+    }
+  }
+| SET VALUE SCONST SORT KEY signed_iconst64
+  {
+    $$.val = &tree.AlterTypeSetValueSortKey{
+      Val: $3,
+      SortKey: $6.int64(),
+    }
+  }
+| SET WIDTH signed_iconst64
+  {
+    cmd, err := tree.NewAlterTypeSetWidth($3.int64())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET CATEGORY SCONST
+  {
+    cmd, err := tree.NewAlterTypeSetCategory($3)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET STATISTICS TARGET signed_iconst64
+  {
+    cmd, err := tree.NewAlterTypeSetStatisticsTarget($4.int64())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET CASE SENSITIVE
+  {
+    $$.val = &tree.AlterTypeSetCaseSensitivity{
+      CaseSensitivity: tree.AlterTypeCaseSensitive,
+    }
+  }
+| SET CASE INSENSITIVE
+  {
+    $$.val = &tree.AlterTypeSetCaseSensitivity{
+      CaseSensitivity: tree.AlterTypeCaseInsensitive,
+    }
+  }
+| SET DISPLAY FORMAT SCONST
+  {
+    $$.val = &tree.AlterTypeSetDisplayFormat{
+      DisplayFormat: $4,
+    }
+  }
+| SET TTL SCONST
+  {
+    cmd, err := tree.NewAlterTypeSetTTL($3)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| DROP TTL
+  {
+    $$.val = &tree.AlterTypeDropTTL{}
+  }
+| SET UNKNOWN VALUE POLICY name
+  {
+    cmd, err := tree.NewAlterTypeSetUnknownPolicy($5, nil /* fallback */)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET UNKNOWN VALUE POLICY name SCONST
+  {
+    fallback := $6
+    cmd, err := tree.NewAlterTypeSetUnknownPolicy($5, &fallback)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET FROZEN
+  {
+    $$.val = &tree.AlterTypeSetFrozen{Frozen: true}
+  }
+| SET UNFROZEN
+  {
+    $$.val = &tree.AlterTypeSetFrozen{Frozen: false}
+  }
+| SET AUDIT RETENTION SCONST
+  {
+    cmd, err := tree.NewAlterTypeSetAuditRetention($4)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| DROP AUDIT RETENTION
+  {
+    $$.val = &tree.AlterTypeDropAuditRetention{}
+  }
+| SET COMPRESSION name
+  {
+    cmd, err := tree.NewAlterTypeSetCompression($3)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET COMPRESSION NONE
+  {
+    $$.val = &tree.AlterTypeSetCompression{
+      Compression: tree.AlterTypeCompressionNone,
+    }
+  }
+| SET NULLS_LA FIRST
+  {
+    $$.val = &tree.AlterTypeSetNullsOrder{
+      NullsOrder: tree.AlterTypeNullsFirst,
+    }
+  }
+| SET NULLS_LA LAST
+  {
+    $$.val = &tree.AlterTypeSetNullsOrder{
+      NullsOrder: tree.AlterTypeNullsLast,
+    }
+  }
+| ENABLE ROW LEVEL SECURITY
+  {
+    $$.val = &tree.AlterTypeSetRLS{Enabled: true}
+  }
+| DISABLE ROW LEVEL SECURITY
+  {
+    $$.val = &tree.AlterTypeSetRLS{Enabled: false}
+  }
+| SET PARTITION HINT SCONST
+  {
+    $$.val = &tree.AlterTypeSetPartitionHint{
+      Hint: $4,
+    }
+  }
+| SET EXTERNAL MAPPING SCONST '=' '>' SCONST
+  {
+    $$.val = &tree.AlterTypeSetExternalMapping{
+      Val: $4,
+      ExternalCode: $7,
+    }
+  }
+| DROP EXTERNAL MAPPING SCONST
+  {
+    $$.val = &tree.AlterTypeDropExternalMapping{
+      Val: $4,
+    }
+  }
+| SOFT DELETE VALUE SCONST
+  {
+    $$.val = &tree.AlterTypeSoftDeleteValue{
+      Val: $4,
+    }
+  }
+| RESTORE VALUE SCONST
+  {
+    $$.val = &tree.AlterTypeRestoreValue{
+      Val: $3,
+    }
+  }
+| SET MAX VALUES signed_iconst64
+  {
+    cmd, err := tree.NewAlterTypeSetMaxValues($4.int64())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET LABELS FROM table_name
+  {
+    $$.val = &tree.AlterTypeSetLabelsFrom{
+      Table: $4.unresolvedObjectName(),
+    }
+  }
+| SET REPLICA IDENTITY DEFAULT
+  {
+    $$.val = &tree.AlterTypeSetReplicaIdentity{
+      Identity: tree.AlterTypeReplicaIdentityDefault,
+    }
+  }
+| SET REPLICA IDENTITY FULL
+  {
+    $$.val = &tree.AlterTypeSetReplicaIdentity{
+      Identity: tree.AlterTypeReplicaIdentityFull,
+    }
+  }
+| SET WIRE FORMAT VERSION signed_iconst64
+  {
+    cmd, err := tree.NewAlterTypeSetWireFormatVersion($5.int64())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET VALIDATION WEBHOOK SCONST
+  {
+    cmd, err := tree.NewAlterTypeSetValidationWebhook($4)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| DROP VALIDATION WEBHOOK
+  {
+    $$.val = &tree.AlterTypeDropValidationWebhook{}
+  }
+| SET VALUE SCONST OPTION name '=' SCONST
+  {
+    $$.val = &tree.AlterTypeSetValueOption{
+      Val: $3,
+      Key: tree.Name($5),
+      Option: $7,
+    }
+  }
+| RESET VALUE SCONST OPTION name
+  {
+    $$.val = &tree.AlterTypeResetValueOption{
+      Val: $3,
+      Key: tree.Name($5),
+    }
+  }
+| SET ATTRIBUTE ORDER '(' name_list ')'
+  {
+    cmd, err := tree.NewAlterTypeSetAttributeOrder($5.nameList().ToStrings())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET OPEN
+  {
+    $$.val = &tree.AlterTypeSetExtensibility{
+      Extensibility: tree.AlterTypeExtensibilityOpen,
+    }
+  }
+| SET CLOSED
+  {
+    $$.val = &tree.AlterTypeSetExtensibility{
+      Extensibility: tree.AlterTypeExtensibilityClosed,
+    }
+  }
+| SET UPGRADE FUNCTION db_object_name
+  {
+    $$.val = &tree.AlterTypeSetUpgradeFunction{
+      Function: $4.unresolvedObjectName(),
+    }
+  }
+| DROP UPGRADE FUNCTION
+  {
+    $$.val = &tree.AlterTypeDropUpgradeFunction{}
+  }
+| SET TELEMETRY SAMPLE RATE numeric_only
+  {
+    rate, _ := constant.Float64Val($5.numVal().AsConstantValue())
+    cmd, err := tree.NewAlterTypeSetTelemetrySampleRate(rate)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET BINDING name
+  {
+    cmd, err := tree.NewAlterTypeSetBinding($3)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET VALUE SCONST CODE signed_iconst64
+  {
+    cmd, err := tree.NewAlterTypeSetValueCode($3, $5.int64())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| SET DEPRECATED
+  {
+    $$.val = &tree.AlterTypeSetDeprecated{}
+  }
+| SET DEPRECATED SCONST
+  {
+    $$.val = &tree.AlterTypeSetDeprecated{Message: $3}
+  }
+| SET NOT DEPRECATED
+  {
+    $$.val = &tree.AlterTypeSetNotDeprecated{}
+  }
+| SET ACCESS PATTERN name
+  {
+    cmd, err := tree.NewAlterTypeSetAccessPattern($4)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = cmd
+  }
+| ADD CAST TO typename WITH FUNCTION db_object_name
+  {
+    $$.val = &tree.AlterTypeAddCast{
+      Target: $4.typeReference(),
+      Function: $7.unresolvedObjectName(),
+      Context: tree.AlterTypeCastExplicit,
+    }
+  }
+| ADD CAST TO typename WITH FUNCTION db_object_name AS ASSIGNMENT
+  {
+    $$.val = &tree.AlterTypeAddCast{
+      Target: $4.typeReference(),
+      Function: $7.unresolvedObjectName(),
+      Context: tree.AlterTypeCastAssignment,
+    }
+  }
+| ADD CAST TO typename WITH FUNCTION db_object_name AS IMPLICIT
+  {
+    $$.val = &tree.AlterTypeAddCast{
+      Target: $4.typeReference(),
+      Function: $7.unresolvedObjectName(),
+      Context: tree.AlterTypeCastImplicit,
+    }
+  }
+| DROP CAST TO typename
+  {
+    $$.val = &tree.AlterTypeDropCast{Target: $4.typeReference()}
+  }
+| REBUILD DEPENDENT INDEXES
+  {
+    $$.val = &tree.AlterTypeRebuildIndexes{}
+  }
+| GRANT privileges TO alter_type_grantees
+  {
+    $$.val = &tree.AlterTypeGrant{
+      Privileges: $2.privilegeList(),
+      Grantees: $4.nameList(),
+    }
+  }
+| REVOKE privileges FROM alter_type_grantees
+  {
+    $$.val = &tree.AlterTypeRevoke{
+      Privileges: $2.privilegeList(),
+      Grantees: $4.nameList(),
+    }
+  }
+| CONVERT USING a_expr
+  {
+    $$.val = &tree.AlterTypeConvertUsing{
+      Using: $3.expr(),
+    }
+  }
+| CONVERT TO TABLE table_name
+  {
+    $$.val = &tree.AlterTypeConvertToTable{
+      Table: $4.unresolvedObjectName(),
+    }
+  }
+| ADD CONSTRAINT constraint_name CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.AlterTypeAddConstraint{
+      Name: tree.Name($3),
+      Expr: $6.expr(),
+    }
+  }
+| ADD CONSTRAINT IF NOT EXISTS constraint_name CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.AlterTypeAddConstraint{
+      Name: tree.Name($6),
+      Expr: $9.expr(),
+      IfNotExists: true,
+    }
+  }
+| ADD CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.AlterTypeAddConstraint{
+      Expr: $4.expr(),
+    }
+  }
+| DROP CONSTRAINT constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterTypeDropConstraint{
+      Constraint: tree.Name($3),
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+
+rename_value_list:
+  SCONST TO SCONST
+  {
+    $$.val = []tree.AlterTypeRenameValuePair{{OldVal: $1, NewVal: $3}}
+  }
+| rename_value_list ',' SCONST TO SCONST
+  {
+    $$.val = append($1.alterTypeRenameValuePairs(), tree.AlterTypeRenameValuePair{OldVal: $3, NewVal: $5})
   }
-| ALTER TYPE error // SHOW HELP: ALTER TYPE
 
 opt_add_val_placement:
   BEFORE SCONST
@@ -2095,6 +2613,22 @@ opt_add_val_placement:
     $$.val = (*tree.AlterTypeAddValuePlacement)(nil)
   }
 
+// alter_type_grantees is a single role, or a parenthesized list of roles to
+// avoid an ambiguity with the list of ALTER TYPE commands.
+alter_type_grantees:
+  role_spec
+  {
+    $$.val = tree.NameList{tree.Name($1)}
+  }
+| '(' role_spec_list ')'
+  {
+    var grantees tree.NameList
+    for _, r := range $2.strs() {
+      grantees = append(grantees, tree.Name(r))
+    }
+    $$.val = grantees
+  }
+
 role_spec_list:
 	role_spec {
 		$$.val = []string{$1}
@@ -11525,14 +12059,17 @@ unreserved_keyword:
 | AGGREGATE
 | ALTER
 | ALWAYS
+| ASSIGNMENT
 | AT
 | ATTRIBUTE
+| AUDIT
 | AUTOMATIC
 | BACKUP
 | BACKUPS
 | BEFORE
 | BEGIN
 | BINARY
+| BINDING
 | BUCKET_COUNT
 | BUNDLE
 | BY
@@ -11540,9 +12077,12 @@ unreserved_keyword:
 | CANCEL
 | CANCELQUERY
 | CASCADE
+| CATEGORY
 | CHANGEFEED
 | CLOSE
+| CLOSED
 | CLUSTER
+| CODE
 | COLUMNS
 | COMMENT
 | COMMENTS
@@ -11550,6 +12090,7 @@ unreserved_keyword:
 | COMMITTED
 | COMPACT
 | COMPLETE
+| COMPRESSION
 | CONFLICT
 | CONFIGURATION
 | CONFIGURATIONS
@@ -11577,12 +12118,17 @@ unreserved_keyword:
 | DELETE
 | DEFAULTS
 | DEFERRED
+| DEPENDENT
+| DEPRECATED
 | DESTINATION
 | DETACHED
+| DISABLE
 | DISCARD
+| DISPLAY
 | DOMAIN
 | DOUBLE
 | DROP
+| ENABLE
 | ENCODING
 | ENCRYPTION_PASSPHRASE
 | ENUM
@@ -11601,11 +12147,14 @@ unreserved_keyword:
 | EXPLAIN
 | EXPORT
 | EXTENSION
+| EXTERNAL
 | FILES
 | FILTER
 | FIRST
 | FOLLOWING
 | FORCE_INDEX
+| FORMAT
+| FROZEN
 | FUNCTION
 | GENERATED
 | GEOMETRYM
@@ -11619,11 +12168,14 @@ unreserved_keyword:
 | GRANTS
 | GROUPS
 | HASH
+| HIDDEN
 | HIGH
+| HINT
 | HISTOGRAM
 | HOUR
 | IDENTITY
 | IMMEDIATE
+| IMPLICIT
 | IMPORT
 | INCLUDE
 | INCLUDING
@@ -11632,6 +12184,7 @@ unreserved_keyword:
 | INDEXES
 | INHERITS
 | INJECT
+| INSENSITIVE
 | INSERT
 | INTERLEAVE
 | INTO_DB
@@ -11644,6 +12197,7 @@ unreserved_keyword:
 | KEYS
 | KMS
 | KV
+| LABELS
 | LANGUAGE
 | LAST
 | LATEST
@@ -11659,8 +12213,10 @@ unreserved_keyword:
 | LOGIN
 | LOOKUP
 | LOW
+| MAPPING
 | MATCH
 | MATERIALIZED
+| MAX
 | MAXVALUE
 | MERGE
 | METHOD
@@ -11702,10 +12258,12 @@ unreserved_keyword:
 | OF
 | OFF
 | OIDS
+| OPEN
 | OPERATOR
 | OPT
 | OPTION
 | OPTIONS
+| ORDERING
 | ORDINALITY
 | OTHERS
 | OVER
@@ -11716,6 +12274,7 @@ unreserved_keyword:
 | PARTITION
 | PARTITIONS
 | PASSWORD
+| PATTERN
 | PAUSE
 | PAUSED
 | PHYSICAL
@@ -11724,6 +12283,7 @@ unreserved_keyword:
 | POINTM
 | POINTZ
 | POINTZM
+| POLICY
 | POLYGONM
 | POLYGONZ
 | POLYGONZM
@@ -11737,8 +12297,10 @@ unreserved_keyword:
 | QUERY
 | RANGE
 | RANGES
+| RATE
 | READ
 | REASSIGN
+| REBUILD
 | RECURRING
 | RECURSIVE
 | REF
@@ -11748,10 +12310,12 @@ unreserved_keyword:
 | RENAME
 | REPEATABLE
 | REPLACE
+| REPLICA
 | RESET
 | RESTORE
 | RESTRICT
 | RESUME
+| RETENTION
 | RETRY
 | REVISION_HISTORY
 | REVOKE
@@ -11767,6 +12331,7 @@ unreserved_keyword:
 | SETTING
 | SETTINGS
 | STATUS
+| SAMPLE
 | SAVEPOINT
 | SCATTER
 | SCHEMA
@@ -11774,6 +12339,8 @@ unreserved_keyword:
 | SCRUB
 | SEARCH
 | SECOND
+| SECURITY
+| SENSITIVE
 | SERIALIZABLE
 | SEQUENCE
 | SEQUENCES
@@ -11791,6 +12358,8 @@ unreserved_keyword:
 | SKIP_MISSING_SEQUENCE_OWNERS
 | SKIP_MISSING_VIEWS
 | SNAPSHOT
+| SOFT
+| SORT
 | SPLIT
 | SQL
 | START
@@ -11806,6 +12375,8 @@ unreserved_keyword:
 | SYSTEM
 | TABLES
 | TABLESPACE
+| TARGET
+| TELEMETRY
 | TEMP
 | TEMPLATE
 | TEMPORARY
@@ -11819,25 +12390,35 @@ unreserved_keyword:
 | TRIGGER
 | TRUNCATE
 | TRUSTED
+| TTL
 | TYPE
 | TYPES
 | THROTTLING
 | UNBOUNDED
 | UNCOMMITTED
+| UNFROZEN
 | UNKNOWN
 | UNLOGGED
 | UNSPLIT
 | UNTIL
 | UPDATE
+| UPGRADE
 | UPSERT
 | USE
 | USERS
 | VALID
 | VALIDATE
+| VALIDATION
 | VALUE
 | VARYING
+| VERSION
 | VIEW
 | VIEWACTIVITY
+| VISIBILITY
+| VISIBLE
+| WEBHOOK
+| WIDTH
+| WIRE
 | WITHIN
 | WITHOUT
 | WRITE
@@ -11,24 +11,57 @@
 package tree
 
 import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 // AlterType represents an ALTER TYPE statement.
 type AlterType struct {
-	Type *UnresolvedObjectName
-	Cmd  AlterTypeCmd
+	Type     *UnresolvedObjectName
+	IfExists bool
+	Cmd      AlterTypeCmd
 }
 
 // Format implements the NodeFormatter interface.
 func (node *AlterType) Format(ctx *FmtCtx) {
 	ctx.WriteString("ALTER TYPE ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
 	ctx.FormatNode(node.Type)
 	ctx.FormatNode(node.Cmd)
 }
 
+// Cmds returns the commands of the statement.
+func (node *AlterType) Cmds() []AlterTypeCmd {
+	if cmds, ok := node.Cmd.(*AlterTypeCmds); ok {
+		return *cmds
+	}
+	return []AlterTypeCmd{node.Cmd}
+}
+
+// TelemetryCounters returns the telemetry counters of all the commands of the
+// statement.
+func (node *AlterType) TelemetryCounters() []telemetry.Counter {
+	cmds := node.Cmds()
+	counters := make([]telemetry.Counter, len(cmds))
+	for i, cmd := range cmds {
+		counters[i] = cmd.TelemetryCounter()
+	}
+	return counters
+}
+
 // AlterTypeCmd represents a type modification operation.
 type AlterTypeCmd interface {
 	NodeFormatter
@@ -38,23 +71,136 @@ type AlterTypeCmd interface {
 	TelemetryCounter() telemetry.Counter
 }
 
-func (*AlterTypeAddValue) alterTypeCmd()    {}
-func (*AlterTypeRenameValue) alterTypeCmd() {}
-func (*AlterTypeRename) alterTypeCmd()      {}
-func (*AlterTypeSetSchema) alterTypeCmd()   {}
-func (*AlterTypeOwner) alterTypeCmd()       {}
+func (*AlterTypeAddValue) alterTypeCmd()               {}
+func (*AlterTypeRenameValue) alterTypeCmd()            {}
+func (*AlterTypeRename) alterTypeCmd()                 {}
+func (*AlterTypeSetSchema) alterTypeCmd()              {}
+func (*AlterTypeOwner) alterTypeCmd()                  {}
+func (*AlterTypeSetOrdering) alterTypeCmd()            {}
+func (*AlterTypeSetDefault) alterTypeCmd()             {}
+func (*AlterTypeDropDefault) alterTypeCmd()            {}
+func (*AlterTypeSetVisibility) alterTypeCmd()          {}
+func (*AlterTypeSetValueSortKey) alterTypeCmd()        {}
+func (*AlterTypeConvertUsing) alterTypeCmd()           {}
+func (*AlterTypeAddConstraint) alterTypeCmd()          {}
+func (*AlterTypeDropConstraint) alterTypeCmd()         {}
+func (*AlterTypeRenameValues) alterTypeCmd()           {}
+func (*AlterTypeSetWidth) alterTypeCmd()               {}
+func (*AlterTypeSetCategory) alterTypeCmd()            {}
+func (*AlterTypeSetStatisticsTarget) alterTypeCmd()    {}
+func (*AlterTypeSetCaseSensitivity) alterTypeCmd()     {}
+func (*AlterTypeConvertToTable) alterTypeCmd()         {}
+func (*AlterTypeSetDisplayFormat) alterTypeCmd()       {}
+func (*AlterTypeGrant) alterTypeCmd()                  {}
+func (*AlterTypeRevoke) alterTypeCmd()                 {}
+func (*AlterTypeSetTTL) alterTypeCmd()                 {}
+func (*AlterTypeDropTTL) alterTypeCmd()                {}
+func (*AlterTypeRebuildIndexes) alterTypeCmd()         {}
+func (*AlterTypeSetUnknownPolicy) alterTypeCmd()       {}
+func (*AlterTypeSetFrozen) alterTypeCmd()              {}
+func (*AlterTypeSetAuditRetention) alterTypeCmd()      {}
+func (*AlterTypeDropAuditRetention) alterTypeCmd()     {}
+func (*AlterTypeSetCompression) alterTypeCmd()         {}
+func (*AlterTypeSetNullsOrder) alterTypeCmd()          {}
+func (*AlterTypeSetRLS) alterTypeCmd()                 {}
+func (*AlterTypeSetPartitionHint) alterTypeCmd()       {}
+func (*AlterTypeSetExternalMapping) alterTypeCmd()     {}
+func (*AlterTypeDropExternalMapping) alterTypeCmd()    {}
+func (*AlterTypeSoftDeleteValue) alterTypeCmd()        {}
+func (*AlterTypeRestoreValue) alterTypeCmd()           {}
+func (*AlterTypeSetMaxValues) alterTypeCmd()           {}
+func (*AlterTypeSetLabelsFrom) alterTypeCmd()          {}
+func (*AlterTypeSetReplicaIdentity) alterTypeCmd()     {}
+func (*AlterTypeSetWireFormatVersion) alterTypeCmd()   {}
+func (*AlterTypeSetValidationWebhook) alterTypeCmd()   {}
+func (*AlterTypeDropValidationWebhook) alterTypeCmd()  {}
+func (*AlterTypeSetValueOption) alterTypeCmd()         {}
+func (*AlterTypeResetValueOption) alterTypeCmd()       {}
+func (*AlterTypeSetAttributeOrder) alterTypeCmd()      {}
+func (*AlterTypeSetExtensibility) alterTypeCmd()       {}
+func (*AlterTypeSetUpgradeFunction) alterTypeCmd()     {}
+func (*AlterTypeDropUpgradeFunction) alterTypeCmd()    {}
+func (*AlterTypeSetTelemetrySampleRate) alterTypeCmd() {}
+func (*AlterTypeSetBinding) alterTypeCmd()             {}
+func (*AlterTypeSetValueCode) alterTypeCmd()           {}
+func (*AlterTypeSetDeprecated) alterTypeCmd()          {}
+func (*AlterTypeSetNotDeprecated) alterTypeCmd()       {}
+func (*AlterTypeSetAccessPattern) alterTypeCmd()       {}
+func (*AlterTypeAddCast) alterTypeCmd()                {}
+func (*AlterTypeDropCast) alterTypeCmd()               {}
+func (*AlterTypeCmds) alterTypeCmd()                   {}
 
 var _ AlterTypeCmd = &AlterTypeAddValue{}
 var _ AlterTypeCmd = &AlterTypeRenameValue{}
 var _ AlterTypeCmd = &AlterTypeRename{}
 var _ AlterTypeCmd = &AlterTypeSetSchema{}
 var _ AlterTypeCmd = &AlterTypeOwner{}
+var _ AlterTypeCmd = &AlterTypeSetOrdering{}
+var _ AlterTypeCmd = &AlterTypeSetDefault{}
+var _ AlterTypeCmd = &AlterTypeDropDefault{}
+var _ AlterTypeCmd = &AlterTypeSetVisibility{}
+var _ AlterTypeCmd = &AlterTypeSetValueSortKey{}
+var _ AlterTypeCmd = &AlterTypeConvertUsing{}
+var _ AlterTypeCmd = &AlterTypeAddConstraint{}
+var _ AlterTypeCmd = &AlterTypeDropConstraint{}
+var _ AlterTypeCmd = &AlterTypeRenameValues{}
+var _ AlterTypeCmd = &AlterTypeSetWidth{}
+var _ AlterTypeCmd = &AlterTypeSetCategory{}
+var _ AlterTypeCmd = &AlterTypeSetStatisticsTarget{}
+var _ AlterTypeCmd = &AlterTypeSetCaseSensitivity{}
+var _ AlterTypeCmd = &AlterTypeConvertToTable{}
+var _ AlterTypeCmd = &AlterTypeSetDisplayFormat{}
+var _ AlterTypeCmd = &AlterTypeGrant{}
+var _ AlterTypeCmd = &AlterTypeRevoke{}
+var _ AlterTypeCmd = &AlterTypeSetTTL{}
+var _ AlterTypeCmd = &AlterTypeDropTTL{}
+var _ AlterTypeCmd = &AlterTypeRebuildIndexes{}
+var _ AlterTypeCmd = &AlterTypeSetUnknownPolicy{}
+var _ AlterTypeCmd = &AlterTypeSetFrozen{}
+var _ AlterTypeCmd = &AlterTypeSetAuditRetention{}
+var _ AlterTypeCmd = &AlterTypeDropAuditRetention{}
+var _ AlterTypeCmd = &AlterTypeSetCompression{}
+var _ AlterTypeCmd = &AlterTypeSetNullsOrder{}
+var _ AlterTypeCmd = &AlterTypeSetRLS{}
+var _ AlterTypeCmd = &AlterTypeSetPartitionHint{}
+var _ AlterTypeCmd = &AlterTypeSetExternalMapping{}
+var _ AlterTypeCmd = &AlterTypeDropExternalMapping{}
+var _ AlterTypeCmd = &AlterTypeSoftDeleteValue{}
+var _ AlterTypeCmd = &AlterTypeRestoreValue{}
+var _ AlterTypeCmd = &AlterTypeSetMaxValues{}
+var _ AlterTypeCmd = &AlterTypeSetLabelsFrom{}
+var _ AlterTypeCmd = &AlterTypeSetReplicaIdentity{}
+var _ AlterTypeCmd = &AlterTypeSetWireFormatVersion{}
+var _ AlterTypeCmd = &AlterTypeSetValidationWebhook{}
+var _ AlterTypeCmd = &AlterTypeDropValidationWebhook{}
+var _ AlterTypeCmd = &AlterTypeSetValueOption{}
+var _ AlterTypeCmd = &AlterTypeResetValueOption{}
+var _ AlterTypeCmd = &AlterTypeSetAttributeOrder{}
+var _ AlterTypeCmd = &AlterTypeSetExtensibility{}
+var _ AlterTypeCmd = &AlterTypeSetUpgradeFunction{}
+var _ AlterTypeCmd = &AlterTypeDropUpgradeFunction{}
+var _ AlterTypeCmd = &AlterTypeSetTelemetrySampleRate{}
+var _ AlterTypeCmd = &AlterTypeSetBinding{}
+var _ AlterTypeCmd = &AlterTypeSetValueCode{}
+var _ AlterTypeCmd = &AlterTypeSetDeprecated{}
+var _ AlterTypeCmd = &AlterTypeSetNotDeprecated{}
+var _ AlterTypeCmd = &AlterTypeSetAccessPattern{}
+var _ AlterTypeCmd = &AlterTypeAddCast{}
+var _ AlterTypeCmd = &AlterTypeDropCast{}
+var _ AlterTypeCmd = &AlterTypeCmds{}
 
 // AlterTypeAddValue represents an ALTER TYPE ADD VALUE command.
 type AlterTypeAddValue struct {
 	NewVal      string
 	IfNotExists bool
 	Placement   *AlterTypeAddValuePlacement
+	// NotValid indicates that data depending on the type is not validated
+	// when the value is added.
+	NotValid bool
+	// ConcurrencyToken is an internal hint for the executor to detect
+	// conflicting concurrent additions of values. It is never set by the
+	// parser and is only formatted with FmtShowConcurrencyTokens.
+	ConcurrencyToken string
 }
 
 // Format implements the NodeFormatter interface.
@@ -72,13 +218,55 @@ func (node *AlterTypeAddValue) Format(ctx *FmtCtx) {
 		}
 		lex.EncodeSQLString(&ctx.Buffer, node.Placement.ExistingVal)
 	}
+	if node.NotValid {
+		ctx.WriteString(" NOT VALID")
+	}
+	if node.ConcurrencyToken != "" && ctx.HasFlags(FmtShowConcurrencyTokens) {
+		ctx.WriteString(" /* concurrency token: ")
+		// Keep the token from ending or nesting the comment.
+		ctx.WriteString(commentEscaper.Replace(node.ConcurrencyToken))
+		ctx.WriteString(" */")
+	}
 }
 
+var commentEscaper = strings.NewReplacer("/*", "/ *", "*/", "* /")
+
 // TelemetryCounter implements the AlterTypeCmd interface.
 func (node *AlterTypeAddValue) TelemetryCounter() telemetry.Counter {
+	if node.NotValid {
+		return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "add_value_not_valid")
+	}
 	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "add_value")
 }
 
+// IsNoOp returns whether the command has no effect on an enum with the given
+// existing values, i.e. whether IF NOT EXISTS was specified and the value is
+// already present.
+func (node *AlterTypeAddValue) IsNoOp(existing []string) bool {
+	if !node.IfNotExists {
+		return false
+	}
+	for _, v := range existing {
+		if v == node.NewVal {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the command is meaningful on its own, i.e. that the new
+// value is not empty and that it is not placed relative to itself.
+func (node *AlterTypeAddValue) Validate() error {
+	if node.NewVal == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "enum label cannot be empty")
+	}
+	if node.Placement != nil && node.Placement.ExistingVal == node.NewVal {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"enum label %q cannot be placed relative to itself", node.NewVal)
+	}
+	return nil
+}
+
 // AlterTypeAddValuePlacement represents the placement clause for an ALTER
 // TYPE ADD VALUE command ([BEFORE | AFTER] value).
 type AlterTypeAddValuePlacement struct {
@@ -113,7 +301,7 @@ type AlterTypeRename struct {
 // Format implements the NodeFormatter interface.
 func (node *AlterTypeRename) Format(ctx *FmtCtx) {
 	ctx.WriteString(" RENAME TO ")
-	ctx.WriteString(node.NewName)
+	ctx.FormatNameP(&node.NewName)
 }
 
 // TelemetryCounter implements the AlterTypeCmd interface.
@@ -129,7 +317,7 @@ type AlterTypeSetSchema struct {
 // Format implements the NodeFormatter interface.
 func (node *AlterTypeSetSchema) Format(ctx *FmtCtx) {
 	ctx.WriteString(" SET SCHEMA ")
-	ctx.WriteString(node.Schema)
+	ctx.FormatNameP(&node.Schema)
 }
 
 // TelemetryCounter implements the AlterTypeCmd interface.
@@ -152,3 +340,1448 @@ func (node *AlterTypeOwner) Format(ctx *FmtCtx) {
 func (node *AlterTypeOwner) TelemetryCounter() telemetry.Counter {
 	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "owner")
 }
+
+// AlterTypeOrdering represents the ordering guarantee given by a type.
+type AlterTypeOrdering int
+
+// AlterTypeOrdering values.
+const (
+	AlterTypeOrderingNone AlterTypeOrdering = iota
+	AlterTypeOrderingStrict
+)
+
+var alterTypeOrderingName = [...]string{
+	AlterTypeOrderingNone:   "NONE",
+	AlterTypeOrderingStrict: "STRICT",
+}
+
+func (o AlterTypeOrdering) String() string {
+	return alterTypeOrderingName[o]
+}
+
+// AlterTypeSetOrdering represents an ALTER TYPE SET ORDERING command.
+type AlterTypeSetOrdering struct {
+	Ordering AlterTypeOrdering
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetOrdering) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET ORDERING ")
+	ctx.WriteString(node.Ordering.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetOrdering) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_ordering")
+}
+
+// AlterTypeSetDefault represents an ALTER TYPE SET DEFAULT command.
+type AlterTypeSetDefault struct {
+	Default Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetDefault) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET DEFAULT ")
+	ctx.FormatNode(node.Default)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetDefault) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_default")
+}
+
+// AlterTypeDropDefault represents an ALTER TYPE DROP DEFAULT command.
+type AlterTypeDropDefault struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropDefault) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP DEFAULT")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropDefault) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_default")
+}
+
+// AlterTypeVisibility represents whether a type is visible to introspection,
+// e.g. through pg_catalog.
+type AlterTypeVisibility int
+
+// AlterTypeVisibility values.
+const (
+	AlterTypeVisibilityVisible AlterTypeVisibility = iota
+	AlterTypeVisibilityHidden
+)
+
+var alterTypeVisibilityName = [...]string{
+	AlterTypeVisibilityVisible: "VISIBLE",
+	AlterTypeVisibilityHidden:  "HIDDEN",
+}
+
+func (v AlterTypeVisibility) String() string {
+	return alterTypeVisibilityName[v]
+}
+
+// AlterTypeSetVisibility represents an ALTER TYPE SET VISIBILITY command.
+type AlterTypeSetVisibility struct {
+	Visibility AlterTypeVisibility
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetVisibility) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET VISIBILITY ")
+	ctx.WriteString(node.Visibility.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetVisibility) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_visibility")
+}
+
+// AlterTypeSetValueSortKey represents an ALTER TYPE SET VALUE SORT KEY command,
+// which assigns an explicit sort key to a value of an ordered enum.
+type AlterTypeSetValueSortKey struct {
+	Val     string
+	SortKey int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetValueSortKey) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET VALUE ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+	ctx.Printf(" SORT KEY %d", node.SortKey)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetValueSortKey) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_value_sort_key")
+}
+
+// AlterTypeConvertUsing represents an ALTER TYPE CONVERT USING command, which
+// describes how to transform the existing values of the type.
+type AlterTypeConvertUsing struct {
+	Using Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeConvertUsing) Format(ctx *FmtCtx) {
+	ctx.WriteString(" CONVERT USING ")
+	ctx.FormatNode(node.Using)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeConvertUsing) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "convert_using")
+}
+
+// AlterTypeAddConstraint represents an ALTER TYPE ADD CONSTRAINT command, which
+// adds a check constraint to a domain type.
+type AlterTypeAddConstraint struct {
+	// Name is empty if the constraint is unnamed.
+	Name Name
+	Expr Expr
+	// IfNotExists is only set for named constraints.
+	IfNotExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD ")
+	if node.IfNotExists {
+		ctx.WriteString("CONSTRAINT IF NOT EXISTS ")
+		ctx.FormatNode(&node.Name)
+		ctx.WriteString(" CHECK (")
+		ctx.FormatNode(node.Expr)
+		ctx.WriteByte(')')
+		return
+	}
+	ctx.FormatNode(&CheckConstraintTableDef{Name: node.Name, Expr: node.Expr})
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeAddConstraint) TelemetryCounter() telemetry.Counter {
+	if node.IfNotExists {
+		return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "add_constraint_if_not_exists")
+	}
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "add_constraint")
+}
+
+// AlterTypeDropConstraint represents an ALTER TYPE DROP CONSTRAINT command.
+type AlterTypeDropConstraint struct {
+	Constraint   Name
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP CONSTRAINT ")
+	ctx.FormatNode(&node.Constraint)
+	if node.DropBehavior != DropDefault {
+		ctx.Printf(" %s", node.DropBehavior)
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropConstraint) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_constraint")
+}
+
+// AlterTypeRenameValuePair is a single old to new value renaming of an ALTER
+// TYPE RENAME VALUES command.
+type AlterTypeRenameValuePair struct {
+	OldVal string
+	NewVal string
+}
+
+// AlterTypeRenameValues represents an ALTER TYPE RENAME VALUES command, which
+// renames several values of an enum as a single unit. It should be constructed
+// with NewAlterTypeRenameValues.
+type AlterTypeRenameValues struct {
+	Pairs []AlterTypeRenameValuePair
+}
+
+// NewAlterTypeRenameValues returns an AlterTypeRenameValues for the given
+// pairs. An error is returned if a value is renamed more than once or if two
+// values are given the same new name.
+func NewAlterTypeRenameValues(pairs []AlterTypeRenameValuePair) (*AlterTypeRenameValues, error) {
+	oldVals := make(map[string]struct{}, len(pairs))
+	newVals := make(map[string]struct{}, len(pairs))
+	for _, p := range pairs {
+		if _, ok := oldVals[p.OldVal]; ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"enum value %q is renamed more than once", p.OldVal)
+		}
+		oldVals[p.OldVal] = struct{}{}
+		if _, ok := newVals[p.NewVal]; ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"more than one enum value is renamed to %q", p.NewVal)
+		}
+		newVals[p.NewVal] = struct{}{}
+	}
+	return &AlterTypeRenameValues{Pairs: pairs}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeRenameValues) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RENAME VALUES (")
+	for i, p := range node.Pairs {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		lex.EncodeSQLString(&ctx.Buffer, p.OldVal)
+		ctx.WriteString(" TO ")
+		lex.EncodeSQLString(&ctx.Buffer, p.NewVal)
+	}
+	ctx.WriteByte(')')
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeRenameValues) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "rename_values")
+}
+
+// AlterTypeSetWidth represents an ALTER TYPE SET WIDTH command, which widens
+// the physical representation of an enum that has outgrown its initial width.
+// It should be constructed with NewAlterTypeSetWidth.
+type AlterTypeSetWidth struct {
+	Width int64
+}
+
+// NewAlterTypeSetWidth returns an AlterTypeSetWidth for the given width, which
+// must be positive.
+func NewAlterTypeSetWidth(width int64) (*AlterTypeSetWidth, error) {
+	if width <= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"width must be positive, got %d", width)
+	}
+	return &AlterTypeSetWidth{Width: width}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetWidth) Format(ctx *FmtCtx) {
+	ctx.Printf(" SET WIDTH %d", node.Width)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetWidth) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_width")
+}
+
+// AlterTypeSetCategory represents an ALTER TYPE SET CATEGORY command, which
+// overrides the category of the type reported in pg_catalog. It should be
+// constructed with NewAlterTypeSetCategory.
+type AlterTypeSetCategory struct {
+	Category string
+}
+
+// NewAlterTypeSetCategory returns an AlterTypeSetCategory for the given
+// category code, which must be a single character.
+func NewAlterTypeSetCategory(category string) (*AlterTypeSetCategory, error) {
+	if utf8.RuneCountInString(category) != 1 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"category must be a single character, got %q", category)
+	}
+	return &AlterTypeSetCategory{Category: category}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetCategory) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET CATEGORY ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Category)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetCategory) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_category")
+}
+
+// AlterTypeSetStatisticsTarget represents an ALTER TYPE SET STATISTICS TARGET
+// command, which sets the statistics target used for columns of the type. It
+// should be constructed with NewAlterTypeSetStatisticsTarget.
+type AlterTypeSetStatisticsTarget struct {
+	Target int64
+}
+
+// NewAlterTypeSetStatisticsTarget returns an AlterTypeSetStatisticsTarget for
+// the given target, which must not be negative.
+func NewAlterTypeSetStatisticsTarget(target int64) (*AlterTypeSetStatisticsTarget, error) {
+	if target < 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"statistics target must not be negative, got %d", target)
+	}
+	return &AlterTypeSetStatisticsTarget{Target: target}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetStatisticsTarget) Format(ctx *FmtCtx) {
+	ctx.Printf(" SET STATISTICS TARGET %d", node.Target)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetStatisticsTarget) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_statistics_target")
+}
+
+// AlterTypeCaseSensitivity represents whether comparisons of values of a type
+// are case-sensitive.
+type AlterTypeCaseSensitivity int
+
+// AlterTypeCaseSensitivity values.
+const (
+	AlterTypeCaseSensitive AlterTypeCaseSensitivity = iota
+	AlterTypeCaseInsensitive
+)
+
+var alterTypeCaseSensitivityName = [...]string{
+	AlterTypeCaseSensitive:   "SENSITIVE",
+	AlterTypeCaseInsensitive: "INSENSITIVE",
+}
+
+func (c AlterTypeCaseSensitivity) String() string {
+	return alterTypeCaseSensitivityName[c]
+}
+
+// AlterTypeSetCaseSensitivity represents an ALTER TYPE SET CASE
+// { SENSITIVE | INSENSITIVE } command.
+type AlterTypeSetCaseSensitivity struct {
+	CaseSensitivity AlterTypeCaseSensitivity
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetCaseSensitivity) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET CASE ")
+	ctx.WriteString(node.CaseSensitivity.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetCaseSensitivity) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_case_sensitivity")
+}
+
+// AlterTypeConvertToTable represents an ALTER TYPE CONVERT TO TABLE command,
+// which converts an enum to one backed by a lookup table.
+type AlterTypeConvertToTable struct {
+	Table *UnresolvedObjectName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeConvertToTable) Format(ctx *FmtCtx) {
+	ctx.WriteString(" CONVERT TO TABLE ")
+	ctx.FormatNode(node.Table)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeConvertToTable) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "convert_to_table")
+}
+
+// AlterTypeSetDisplayFormat represents an ALTER TYPE SET DISPLAY FORMAT
+// command, which records how the values of the type should be rendered, e.g.
+// in UIs. It only changes metadata of the type.
+type AlterTypeSetDisplayFormat struct {
+	DisplayFormat string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetDisplayFormat) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET DISPLAY FORMAT ")
+	lex.EncodeSQLString(&ctx.Buffer, node.DisplayFormat)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetDisplayFormat) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_display_format")
+}
+
+// AlterTypeSetTTL represents an ALTER TYPE SET TTL command, which records
+// how long a session-scoped or temporary type should be kept around. It should
+// be constructed with NewAlterTypeSetTTL.
+type AlterTypeSetTTL struct {
+	TTL time.Duration
+}
+
+// NewAlterTypeSetTTL returns an AlterTypeSetTTL for the given duration string,
+// e.g. '1h30m', which must describe a positive duration.
+func NewAlterTypeSetTTL(ttl string) (*AlterTypeSetTTL, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue, "invalid TTL %q", ttl)
+	}
+	if d <= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"TTL must be positive, got %s", d)
+	}
+	return &AlterTypeSetTTL{TTL: d}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetTTL) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET TTL ")
+	lex.EncodeSQLString(&ctx.Buffer, node.TTL.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetTTL) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_ttl")
+}
+
+// AlterTypeDropTTL represents an ALTER TYPE DROP TTL command, which removes
+// the TTL set by ALTER TYPE SET TTL.
+type AlterTypeDropTTL struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropTTL) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP TTL")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropTTL) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_ttl")
+}
+
+// AlterTypeGrant represents an ALTER TYPE GRANT command, which is equivalent
+// to a GRANT ... ON TYPE statement for the altered type. Several grantees are
+// listed in parentheses, as the list would otherwise be ambiguous with the list
+// of commands of the statement.
+type AlterTypeGrant struct {
+	Privileges privilege.List
+	Grantees   NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeGrant) Format(ctx *FmtCtx) {
+	ctx.WriteString(" GRANT ")
+	node.Privileges.Format(&ctx.Buffer)
+	ctx.WriteString(" TO ")
+	formatAlterTypeGrantees(ctx, node.Grantees)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeGrant) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "grant")
+}
+
+// AlterTypeRevoke represents an ALTER TYPE REVOKE command, which is
+// equivalent to a REVOKE ... ON TYPE statement for the altered type. Its
+// grantees are listed as those of AlterTypeGrant.
+type AlterTypeRevoke struct {
+	Privileges privilege.List
+	Grantees   NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeRevoke) Format(ctx *FmtCtx) {
+	ctx.WriteString(" REVOKE ")
+	node.Privileges.Format(&ctx.Buffer)
+	ctx.WriteString(" FROM ")
+	formatAlterTypeGrantees(ctx, node.Grantees)
+}
+
+// formatAlterTypeGrantees formats the grantees of an AlterTypeGrant or
+// AlterTypeRevoke, in parentheses if there are several of them.
+func formatAlterTypeGrantees(ctx *FmtCtx, grantees NameList) {
+	if len(grantees) == 1 {
+		ctx.FormatNode(&grantees[0])
+		return
+	}
+	ctx.WriteByte('(')
+	ctx.FormatNode(&grantees)
+	ctx.WriteByte(')')
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeRevoke) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "revoke")
+}
+
+// AlterTypeRebuildIndexes represents an ALTER TYPE REBUILD DEPENDENT INDEXES
+// command, which rebuilds the indexes depending on the type, e.g. after the
+// ordering of an enum changed.
+type AlterTypeRebuildIndexes struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeRebuildIndexes) Format(ctx *FmtCtx) {
+	ctx.WriteString(" REBUILD DEPENDENT INDEXES")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeRebuildIndexes) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "rebuild_indexes")
+}
+
+// AlterTypeUnknownPolicy is the policy applied when writing an unknown value
+// of an enum.
+type AlterTypeUnknownPolicy int
+
+// AlterTypeUnknownPolicy values.
+const (
+	// AlterTypeUnknownPolicyError rejects unknown values.
+	AlterTypeUnknownPolicyError AlterTypeUnknownPolicy = iota
+	// AlterTypeUnknownPolicyFallback maps unknown values to a fallback value.
+	AlterTypeUnknownPolicyFallback
+)
+
+var alterTypeUnknownPolicyName = [...]string{
+	AlterTypeUnknownPolicyError:    "ERROR",
+	AlterTypeUnknownPolicyFallback: "FALLBACK",
+}
+
+func (p AlterTypeUnknownPolicy) String() string {
+	return alterTypeUnknownPolicyName[p]
+}
+
+// AlterTypeSetUnknownPolicy represents an ALTER TYPE SET UNKNOWN VALUE POLICY
+// command. It should be constructed with NewAlterTypeSetUnknownPolicy.
+type AlterTypeSetUnknownPolicy struct {
+	Policy AlterTypeUnknownPolicy
+	// Fallback is the value unknown values are mapped to. It is only set for
+	// AlterTypeUnknownPolicyFallback.
+	Fallback string
+}
+
+// NewAlterTypeSetUnknownPolicy returns an AlterTypeSetUnknownPolicy for the
+// named policy, either ERROR or FALLBACK, matched case-insensitively. A
+// fallback value must be given for, and only for, FALLBACK.
+func NewAlterTypeSetUnknownPolicy(
+	policy string, fallback *string,
+) (*AlterTypeSetUnknownPolicy, error) {
+	switch strings.ToUpper(policy) {
+	case AlterTypeUnknownPolicyError.String():
+		if fallback != nil {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unknown value policy ERROR does not take a fallback value")
+		}
+		return &AlterTypeSetUnknownPolicy{Policy: AlterTypeUnknownPolicyError}, nil
+	case AlterTypeUnknownPolicyFallback.String():
+		if fallback == nil || *fallback == "" {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unknown value policy FALLBACK requires a fallback value")
+		}
+		return &AlterTypeSetUnknownPolicy{
+			Policy:   AlterTypeUnknownPolicyFallback,
+			Fallback: *fallback,
+		}, nil
+	default:
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"unknown value policy must be ERROR or FALLBACK, got %s", policy)
+	}
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetUnknownPolicy) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET UNKNOWN VALUE POLICY ")
+	ctx.WriteString(node.Policy.String())
+	if node.Policy == AlterTypeUnknownPolicyFallback {
+		ctx.WriteByte(' ')
+		lex.EncodeSQLString(&ctx.Buffer, node.Fallback)
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetUnknownPolicy) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_unknown_policy")
+}
+
+// AlterTypeSetFrozen represents an ALTER TYPE SET { FROZEN | UNFROZEN }
+// command. Further changes to a frozen type are rejected until it is unfrozen.
+type AlterTypeSetFrozen struct {
+	Frozen bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetFrozen) Format(ctx *FmtCtx) {
+	if node.Frozen {
+		ctx.WriteString(" SET FROZEN")
+	} else {
+		ctx.WriteString(" SET UNFROZEN")
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetFrozen) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_frozen")
+}
+
+// AlterTypeSetAuditRetention represents an ALTER TYPE SET AUDIT RETENTION
+// command, which configures how long the audit records of the type are kept.
+// It should be constructed with NewAlterTypeSetAuditRetention.
+type AlterTypeSetAuditRetention struct {
+	Retention duration.Duration
+}
+
+// NewAlterTypeSetAuditRetention returns an AlterTypeSetAuditRetention for the
+// given interval string, e.g. '30 days', which must describe a positive
+// interval.
+func NewAlterTypeSetAuditRetention(retention string) (*AlterTypeSetAuditRetention, error) {
+	d, err := ParseDInterval(retention)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+			"invalid audit retention %q", retention)
+	}
+	if d.Duration.Compare(duration.Duration{}) <= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"audit retention must be positive, got %s", d.Duration)
+	}
+	return &AlterTypeSetAuditRetention{Retention: d.Duration}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetAuditRetention) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET AUDIT RETENTION ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Retention.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetAuditRetention) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_audit_retention")
+}
+
+// AlterTypeDropAuditRetention represents an ALTER TYPE DROP AUDIT RETENTION
+// command, which removes the retention set by ALTER TYPE SET AUDIT RETENTION.
+type AlterTypeDropAuditRetention struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropAuditRetention) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP AUDIT RETENTION")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropAuditRetention) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_audit_retention")
+}
+
+// AlterTypeCompression is the compression applied to the dictionary of values
+// of an enum.
+type AlterTypeCompression int
+
+// AlterTypeCompression values.
+const (
+	AlterTypeCompressionNone AlterTypeCompression = iota
+	AlterTypeCompressionLZ4
+)
+
+var alterTypeCompressionName = [...]string{
+	AlterTypeCompressionNone: "none",
+	AlterTypeCompressionLZ4:  "lz4",
+}
+
+func (c AlterTypeCompression) String() string {
+	return alterTypeCompressionName[c]
+}
+
+// AlterTypeSetCompression represents an ALTER TYPE SET COMPRESSION command.
+// It should be constructed with NewAlterTypeSetCompression.
+type AlterTypeSetCompression struct {
+	Compression AlterTypeCompression
+}
+
+// NewAlterTypeSetCompression returns an AlterTypeSetCompression for the named
+// compression, matched case-insensitively.
+func NewAlterTypeSetCompression(compression string) (*AlterTypeSetCompression, error) {
+	for c, name := range alterTypeCompressionName {
+		if strings.EqualFold(compression, name) {
+			return &AlterTypeSetCompression{Compression: AlterTypeCompression(c)}, nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+		"unknown compression %s, must be one of %s",
+		compression, strings.Join(alterTypeCompressionName[:], ", "))
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetCompression) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET COMPRESSION ")
+	ctx.WriteString(node.Compression.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetCompression) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_compression")
+}
+
+// AlterTypeNullsOrder is the default ordering of NULLs among the values of a
+// type.
+type AlterTypeNullsOrder int
+
+// AlterTypeNullsOrder values.
+const (
+	AlterTypeNullsFirst AlterTypeNullsOrder = iota
+	AlterTypeNullsLast
+)
+
+var alterTypeNullsOrderName = [...]string{
+	AlterTypeNullsFirst: "FIRST",
+	AlterTypeNullsLast:  "LAST",
+}
+
+func (o AlterTypeNullsOrder) String() string {
+	return alterTypeNullsOrderName[o]
+}
+
+// AlterTypeSetNullsOrder represents an ALTER TYPE SET NULLS { FIRST | LAST }
+// command, which sets the default ordering of NULLs among the values of the
+// type, e.g. in indexes.
+type AlterTypeSetNullsOrder struct {
+	NullsOrder AlterTypeNullsOrder
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetNullsOrder) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET NULLS ")
+	ctx.WriteString(node.NullsOrder.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetNullsOrder) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_nulls_order")
+}
+
+// AlterTypeSetRLS represents an ALTER TYPE { ENABLE | DISABLE } ROW LEVEL
+// SECURITY command, which marks whether row-level security applies to the
+// values of a composite type.
+type AlterTypeSetRLS struct {
+	Enabled bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetRLS) Format(ctx *FmtCtx) {
+	if node.Enabled {
+		ctx.WriteString(" ENABLE ROW LEVEL SECURITY")
+	} else {
+		ctx.WriteString(" DISABLE ROW LEVEL SECURITY")
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetRLS) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_rls")
+}
+
+// AlterTypeSetPartitionHint represents an ALTER TYPE SET PARTITION HINT
+// command, which records a hint for the planner about tables partitioned by
+// the values of an enum.
+type AlterTypeSetPartitionHint struct {
+	Hint string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetPartitionHint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET PARTITION HINT ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Hint)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetPartitionHint) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_partition_hint")
+}
+
+// AlterTypeSetExternalMapping represents an ALTER TYPE SET EXTERNAL MAPPING
+// command, which maps a value of an enum to the code representing it in an
+// external system.
+type AlterTypeSetExternalMapping struct {
+	Val          string
+	ExternalCode string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetExternalMapping) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET EXTERNAL MAPPING ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+	ctx.WriteString(" => ")
+	lex.EncodeSQLString(&ctx.Buffer, node.ExternalCode)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetExternalMapping) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_external_mapping")
+}
+
+// AlterTypeDropExternalMapping represents an ALTER TYPE DROP EXTERNAL MAPPING
+// command, which removes the mapping of a value set by ALTER TYPE SET
+// EXTERNAL MAPPING.
+type AlterTypeDropExternalMapping struct {
+	Val string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropExternalMapping) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP EXTERNAL MAPPING ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropExternalMapping) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_external_mapping")
+}
+
+// AlterTypeSoftDeleteValue represents an ALTER TYPE SOFT DELETE VALUE command,
+// which prevents new writes of a value of an enum being phased out while
+// keeping the existing data using it readable.
+type AlterTypeSoftDeleteValue struct {
+	Val string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSoftDeleteValue) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SOFT DELETE VALUE ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSoftDeleteValue) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "soft_delete_value")
+}
+
+// AlterTypeRestoreValue represents an ALTER TYPE RESTORE VALUE command, which
+// undoes an ALTER TYPE SOFT DELETE VALUE.
+type AlterTypeRestoreValue struct {
+	Val string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeRestoreValue) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RESTORE VALUE ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeRestoreValue) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "restore_value")
+}
+
+// AlterTypeSetMaxValues represents an ALTER TYPE SET MAX VALUES command, which
+// limits the number of values of an enum: adding values beyond the limit is
+// rejected. It should be constructed with NewAlterTypeSetMaxValues.
+type AlterTypeSetMaxValues struct {
+	MaxValues int64
+}
+
+// NewAlterTypeSetMaxValues returns an AlterTypeSetMaxValues for the given
+// limit, which must be positive.
+func NewAlterTypeSetMaxValues(maxValues int64) (*AlterTypeSetMaxValues, error) {
+	if maxValues <= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"maximum number of values must be positive, got %d", maxValues)
+	}
+	return &AlterTypeSetMaxValues{MaxValues: maxValues}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetMaxValues) Format(ctx *FmtCtx) {
+	ctx.Printf(" SET MAX VALUES %d", node.MaxValues)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetMaxValues) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_max_values")
+}
+
+// AlterTypeSetLabelsFrom represents an ALTER TYPE SET LABELS FROM command,
+// which names the table holding the localized labels of the values of an
+// enum.
+type AlterTypeSetLabelsFrom struct {
+	Table *UnresolvedObjectName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetLabelsFrom) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET LABELS FROM ")
+	ctx.FormatNode(node.Table)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetLabelsFrom) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_labels_from")
+}
+
+// AlterTypeReplicaIdentity is the replica identity of a type, which controls
+// how much of the old value of a column of the type is recorded by
+// changefeeds when the value is updated.
+type AlterTypeReplicaIdentity int
+
+// AlterTypeReplicaIdentity values.
+const (
+	AlterTypeReplicaIdentityDefault AlterTypeReplicaIdentity = iota
+	AlterTypeReplicaIdentityFull
+)
+
+var alterTypeReplicaIdentityName = [...]string{
+	AlterTypeReplicaIdentityDefault: "DEFAULT",
+	AlterTypeReplicaIdentityFull:    "FULL",
+}
+
+func (i AlterTypeReplicaIdentity) String() string {
+	return alterTypeReplicaIdentityName[i]
+}
+
+// AlterTypeSetReplicaIdentity represents an ALTER TYPE SET REPLICA IDENTITY
+// { DEFAULT | FULL } command.
+type AlterTypeSetReplicaIdentity struct {
+	Identity AlterTypeReplicaIdentity
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetReplicaIdentity) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET REPLICA IDENTITY ")
+	ctx.WriteString(node.Identity.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetReplicaIdentity) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_replica_identity")
+}
+
+// AlterTypeSetWireFormatVersion represents an ALTER TYPE SET WIRE FORMAT
+// VERSION command, which selects the version of the binary pgwire encoding of
+// the values of an enum. It should be constructed with
+// NewAlterTypeSetWireFormatVersion.
+type AlterTypeSetWireFormatVersion struct {
+	Version int64
+}
+
+// NewAlterTypeSetWireFormatVersion returns an AlterTypeSetWireFormatVersion
+// for the given version, which must be positive.
+func NewAlterTypeSetWireFormatVersion(version int64) (*AlterTypeSetWireFormatVersion, error) {
+	if version <= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"wire format version must be positive, got %d", version)
+	}
+	return &AlterTypeSetWireFormatVersion{Version: version}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetWireFormatVersion) Format(ctx *FmtCtx) {
+	ctx.Printf(" SET WIRE FORMAT VERSION %d", node.Version)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetWireFormatVersion) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_wire_format_version")
+}
+
+// AlterTypeSetValidationWebhook represents an ALTER TYPE SET VALIDATION
+// WEBHOOK command, which names an external service that has to approve the
+// values added to an enum. It should be constructed with
+// NewAlterTypeSetValidationWebhook.
+type AlterTypeSetValidationWebhook struct {
+	URL string
+}
+
+// NewAlterTypeSetValidationWebhook returns an AlterTypeSetValidationWebhook
+// for the given URL, which must be an absolute https URL.
+func NewAlterTypeSetValidationWebhook(webhook string) (*AlterTypeSetValidationWebhook, error) {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+			"invalid validation webhook %q", webhook)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"validation webhook must be an https URL, got %q", webhook)
+	}
+	return &AlterTypeSetValidationWebhook{URL: webhook}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetValidationWebhook) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET VALIDATION WEBHOOK ")
+	lex.EncodeSQLString(&ctx.Buffer, node.URL)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetValidationWebhook) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_validation_webhook")
+}
+
+// AlterTypeDropValidationWebhook represents an ALTER TYPE DROP VALIDATION
+// WEBHOOK command, which removes the webhook set by ALTER TYPE SET VALIDATION
+// WEBHOOK.
+type AlterTypeDropValidationWebhook struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropValidationWebhook) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP VALIDATION WEBHOOK")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropValidationWebhook) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_validation_webhook")
+}
+
+// AlterTypeSetValueOption represents an ALTER TYPE SET VALUE OPTION command,
+// which annotates a value of an enum with an arbitrary key/value pair.
+type AlterTypeSetValueOption struct {
+	Val    string
+	Key    Name
+	Option string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetValueOption) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET VALUE ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+	ctx.WriteString(" OPTION ")
+	ctx.FormatNode(&node.Key)
+	ctx.WriteString(" = ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Option)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetValueOption) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_value_option")
+}
+
+// AlterTypeResetValueOption represents an ALTER TYPE RESET VALUE OPTION
+// command, which removes an annotation set by ALTER TYPE SET VALUE OPTION.
+type AlterTypeResetValueOption struct {
+	Val string
+	Key Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeResetValueOption) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RESET VALUE ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+	ctx.WriteString(" OPTION ")
+	ctx.FormatNode(&node.Key)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeResetValueOption) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "reset_value_option")
+}
+
+// AlterTypeSetAttributeOrder represents an ALTER TYPE SET ATTRIBUTE ORDER
+// command, which sets the order in which the attributes of a composite type
+// are serialized. It should be constructed with NewAlterTypeSetAttributeOrder.
+type AlterTypeSetAttributeOrder struct {
+	Attributes []string
+}
+
+// NewAlterTypeSetAttributeOrder returns an AlterTypeSetAttributeOrder for the
+// given attributes, which must not contain duplicates.
+func NewAlterTypeSetAttributeOrder(attributes []string) (*AlterTypeSetAttributeOrder, error) {
+	seen := make(map[string]struct{}, len(attributes))
+	for _, a := range attributes {
+		if _, ok := seen[a]; ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"attribute %q is listed more than once", a)
+		}
+		seen[a] = struct{}{}
+	}
+	return &AlterTypeSetAttributeOrder{Attributes: attributes}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetAttributeOrder) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET ATTRIBUTE ORDER (")
+	for i := range node.Attributes {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNameP(&node.Attributes[i])
+	}
+	ctx.WriteString(")")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetAttributeOrder) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_attribute_order")
+}
+
+// AlterTypeExtensibility represents whether an enum is open, with values
+// expected to be added over time, or closed, with a fixed set of values.
+type AlterTypeExtensibility int
+
+// AlterTypeExtensibility values.
+const (
+	AlterTypeExtensibilityOpen AlterTypeExtensibility = iota
+	AlterTypeExtensibilityClosed
+)
+
+var alterTypeExtensibilityName = [...]string{
+	AlterTypeExtensibilityOpen:   "OPEN",
+	AlterTypeExtensibilityClosed: "CLOSED",
+}
+
+func (e AlterTypeExtensibility) String() string {
+	return alterTypeExtensibilityName[e]
+}
+
+// AlterTypeSetExtensibility represents an ALTER TYPE SET { OPEN | CLOSED }
+// command. Values of a closed enum that are unknown to a node are rejected,
+// while those of an open enum are tolerated.
+type AlterTypeSetExtensibility struct {
+	Extensibility AlterTypeExtensibility
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetExtensibility) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET ")
+	ctx.WriteString(node.Extensibility.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetExtensibility) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_extensibility")
+}
+
+// AlterTypeSetUpgradeFunction represents an ALTER TYPE SET UPGRADE FUNCTION
+// command, which names the function transforming the values of the type
+// stored in a legacy encoding when they are read.
+type AlterTypeSetUpgradeFunction struct {
+	Function *UnresolvedObjectName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetUpgradeFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET UPGRADE FUNCTION ")
+	ctx.FormatNode(node.Function)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetUpgradeFunction) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_upgrade_function")
+}
+
+// AlterTypeDropUpgradeFunction represents an ALTER TYPE DROP UPGRADE FUNCTION
+// command, which removes the function set by ALTER TYPE SET UPGRADE FUNCTION.
+type AlterTypeDropUpgradeFunction struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropUpgradeFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP UPGRADE FUNCTION")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropUpgradeFunction) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_upgrade_function")
+}
+
+// AlterTypeSetTelemetrySampleRate represents an ALTER TYPE SET TELEMETRY
+// SAMPLE RATE command, which sets the fraction of the uses of the type that
+// are reported to telemetry. It should be constructed with
+// NewAlterTypeSetTelemetrySampleRate.
+type AlterTypeSetTelemetrySampleRate struct {
+	Rate float64
+}
+
+// NewAlterTypeSetTelemetrySampleRate returns an
+// AlterTypeSetTelemetrySampleRate for the given rate, which must be between 0
+// and 1.
+func NewAlterTypeSetTelemetrySampleRate(rate float64) (*AlterTypeSetTelemetrySampleRate, error) {
+	if !(rate >= 0 && rate <= 1) {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"telemetry sample rate must be between 0 and 1, got %g", rate)
+	}
+	return &AlterTypeSetTelemetrySampleRate{Rate: rate}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetTelemetrySampleRate) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET TELEMETRY SAMPLE RATE ")
+	ctx.WriteString(strconv.FormatFloat(node.Rate, 'f', -1, 64))
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetTelemetrySampleRate) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_telemetry_sample_rate")
+}
+
+// AlterTypeBinding is the time at which the references of dependent objects to
+// a type are resolved.
+type AlterTypeBinding int
+
+// AlterTypeBinding values.
+const (
+	// AlterTypeBindingEarly resolves references when the dependent objects are
+	// created, binding them to the type in its current schema.
+	AlterTypeBindingEarly AlterTypeBinding = iota
+	// AlterTypeBindingLate resolves references by name each time the dependent
+	// objects are used.
+	AlterTypeBindingLate
+)
+
+var alterTypeBindingName = [...]string{
+	AlterTypeBindingEarly: "EARLY",
+	AlterTypeBindingLate:  "LATE",
+}
+
+func (b AlterTypeBinding) String() string {
+	return alterTypeBindingName[b]
+}
+
+// AlterTypeSetBinding represents an ALTER TYPE SET BINDING { EARLY | LATE }
+// command. It should be constructed with NewAlterTypeSetBinding.
+type AlterTypeSetBinding struct {
+	Binding AlterTypeBinding
+}
+
+// NewAlterTypeSetBinding returns an AlterTypeSetBinding for the named
+// binding, matched case-insensitively.
+func NewAlterTypeSetBinding(binding string) (*AlterTypeSetBinding, error) {
+	for b, name := range alterTypeBindingName {
+		if strings.EqualFold(binding, name) {
+			return &AlterTypeSetBinding{Binding: AlterTypeBinding(b)}, nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+		"unknown binding %s, must be one of %s",
+		binding, strings.Join(alterTypeBindingName[:], ", "))
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetBinding) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET BINDING ")
+	ctx.WriteString(node.Binding.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetBinding) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_binding")
+}
+
+// AlterTypeSetValueCode represents an ALTER TYPE SET VALUE CODE command, which
+// pins a value of an enum to the integer code it is exchanged as with external
+// systems, rather than one following the order in which values were added. It
+// should be constructed with NewAlterTypeSetValueCode.
+type AlterTypeSetValueCode struct {
+	Val  string
+	Code int64
+}
+
+// NewAlterTypeSetValueCode returns an AlterTypeSetValueCode for the given
+// value and code, which must not be negative.
+func NewAlterTypeSetValueCode(val string, code int64) (*AlterTypeSetValueCode, error) {
+	if code < 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"code of enum value %q cannot be negative, got %d", val, code)
+	}
+	return &AlterTypeSetValueCode{Val: val, Code: code}, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetValueCode) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET VALUE ")
+	lex.EncodeSQLString(&ctx.Buffer, node.Val)
+	ctx.Printf(" CODE %d", node.Code)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetValueCode) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_value_code")
+}
+
+// AlterTypeSetDeprecated represents an ALTER TYPE SET DEPRECATED command, which
+// marks the type as deprecated so that a notice is emitted when it is used.
+type AlterTypeSetDeprecated struct {
+	// Message is included in the deprecation notice, e.g. to point at the type
+	// to use instead. An empty message is omitted.
+	Message string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetDeprecated) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET DEPRECATED")
+	if node.Message != "" {
+		ctx.WriteByte(' ')
+		lex.EncodeSQLString(&ctx.Buffer, node.Message)
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetDeprecated) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_deprecated")
+}
+
+// AlterTypeSetNotDeprecated represents an ALTER TYPE SET NOT DEPRECATED
+// command, which undoes an ALTER TYPE SET DEPRECATED.
+type AlterTypeSetNotDeprecated struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetNotDeprecated) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET NOT DEPRECATED")
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetNotDeprecated) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_not_deprecated")
+}
+
+// AlterTypeAccessPattern is the pattern in which the values of a type are
+// expected to be accessed, used as a hint by the storage layer.
+type AlterTypeAccessPattern int
+
+// AlterTypeAccessPattern values.
+const (
+	AlterTypeAccessPatternSequential AlterTypeAccessPattern = iota
+	AlterTypeAccessPatternRandom
+)
+
+var alterTypeAccessPatternName = [...]string{
+	AlterTypeAccessPatternSequential: "SEQUENTIAL",
+	AlterTypeAccessPatternRandom:     "RANDOM",
+}
+
+func (p AlterTypeAccessPattern) String() string {
+	return alterTypeAccessPatternName[p]
+}
+
+// AlterTypeSetAccessPattern represents an ALTER TYPE SET ACCESS PATTERN
+// { SEQUENTIAL | RANDOM } command. It should be constructed with
+// NewAlterTypeSetAccessPattern.
+type AlterTypeSetAccessPattern struct {
+	Pattern AlterTypeAccessPattern
+}
+
+// NewAlterTypeSetAccessPattern returns an AlterTypeSetAccessPattern for the
+// named access pattern, matched case-insensitively.
+func NewAlterTypeSetAccessPattern(pattern string) (*AlterTypeSetAccessPattern, error) {
+	for p, name := range alterTypeAccessPatternName {
+		if strings.EqualFold(pattern, name) {
+			return &AlterTypeSetAccessPattern{Pattern: AlterTypeAccessPattern(p)}, nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+		"unknown access pattern %s, must be one of %s",
+		pattern, strings.Join(alterTypeAccessPatternName[:], ", "))
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetAccessPattern) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET ACCESS PATTERN ")
+	ctx.WriteString(node.Pattern.String())
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeSetAccessPattern) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "set_access_pattern")
+}
+
+// AlterTypeCastContext is the context in which a cast defined with ALTER TYPE
+// ADD CAST can be applied.
+type AlterTypeCastContext int
+
+// AlterTypeCastContext values.
+const (
+	// AlterTypeCastExplicit casts are only applied by explicit cast
+	// expressions.
+	AlterTypeCastExplicit AlterTypeCastContext = iota
+	// AlterTypeCastAssignment casts are also applied when assigning to a
+	// column of the target type.
+	AlterTypeCastAssignment
+	// AlterTypeCastImplicit casts are applied in any context.
+	AlterTypeCastImplicit
+)
+
+var alterTypeCastContextName = [...]string{
+	AlterTypeCastExplicit:   "EXPLICIT",
+	AlterTypeCastAssignment: "ASSIGNMENT",
+	AlterTypeCastImplicit:   "IMPLICIT",
+}
+
+func (c AlterTypeCastContext) String() string {
+	return alterTypeCastContextName[c]
+}
+
+// AlterTypeAddCast represents an ALTER TYPE ADD CAST TO command, which defines
+// a cast from the type to another type performed by the given function.
+type AlterTypeAddCast struct {
+	Target   ResolvableTypeReference
+	Function *UnresolvedObjectName
+	Context  AlterTypeCastContext
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddCast) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD CAST TO ")
+	ctx.FormatTypeReference(node.Target)
+	ctx.WriteString(" WITH FUNCTION ")
+	ctx.FormatNode(node.Function)
+	if node.Context != AlterTypeCastExplicit {
+		ctx.WriteString(" AS ")
+		ctx.WriteString(node.Context.String())
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeAddCast) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "add_cast")
+}
+
+// AlterTypeDropCast represents an ALTER TYPE DROP CAST TO command, which
+// removes a cast defined with ALTER TYPE ADD CAST.
+type AlterTypeDropCast struct {
+	Target ResolvableTypeReference
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropCast) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP CAST TO ")
+	ctx.FormatTypeReference(node.Target)
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeDropCast) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "drop_cast")
+}
+
+// AlterTypeCmds represents a list of type alterations performed by a single
+// ALTER TYPE statement.
+type AlterTypeCmds []AlterTypeCmd
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeCmds) Format(ctx *FmtCtx) {
+	for i, n := range *node {
+		if i > 0 {
+			ctx.WriteString(",")
+		}
+		ctx.FormatNode(n)
+	}
+}
+
+// TelemetryCounter implements the AlterTypeCmd interface.
+func (node *AlterTypeCmds) TelemetryCounter() telemetry.Counter {
+	return sqltelemetry.SchemaChangeAlterCounterWithExtra("type", "multiple_commands")
+}
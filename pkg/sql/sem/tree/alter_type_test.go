@@ -0,0 +1,162 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlterTypeAddValueIsNoOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	existing := []string{"a", "b"}
+	testCases := []struct {
+		newVal      string
+		ifNotExists bool
+		expected    bool
+	}{
+		{"a", true, true},
+		{"b", true, true},
+		{"c", true, false},
+		{"a", false, false},
+		{"c", false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%t", tc.newVal, tc.ifNotExists), func(t *testing.T) {
+			cmd := &tree.AlterTypeAddValue{NewVal: tc.newVal, IfNotExists: tc.ifNotExists}
+			require.Equal(t, tc.expected, cmd.IsNoOp(existing))
+		})
+	}
+
+	// Nothing is a no-op on an empty enum.
+	require.False(t, (&tree.AlterTypeAddValue{NewVal: "a", IfNotExists: true}).IsNoOp(nil))
+}
+
+func TestAlterTypeTelemetryCounters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		sql      string
+		expected []tree.AlterTypeCmd
+	}{
+		{
+			`ALTER TYPE t RENAME TO u`,
+			[]tree.AlterTypeCmd{&tree.AlterTypeRename{}},
+		},
+		{
+			`ALTER TYPE t SET SCHEMA s, RENAME TO u, ADD VALUE 'x'`,
+			[]tree.AlterTypeCmd{&tree.AlterTypeSetSchema{}, &tree.AlterTypeRename{}, &tree.AlterTypeAddValue{}},
+		},
+		{
+			`ALTER TYPE t ADD CONSTRAINT IF NOT EXISTS c CHECK (value > 0), ADD CONSTRAINT d CHECK (value < 10)`,
+			[]tree.AlterTypeCmd{&tree.AlterTypeAddConstraint{IfNotExists: true}, &tree.AlterTypeAddConstraint{}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := parser.ParseOne(tc.sql)
+			require.NoError(t, err)
+			counters := stmt.AST.(*tree.AlterType).TelemetryCounters()
+			require.Len(t, counters, len(tc.expected))
+			for i, cmd := range tc.expected {
+				require.Equal(t, cmd.TelemetryCounter(), counters[i])
+			}
+		})
+	}
+
+	// Guarded and unguarded constraint adds are counted separately.
+	require.True(t, (&tree.AlterTypeAddConstraint{IfNotExists: true}).TelemetryCounter() !=
+		(&tree.AlterTypeAddConstraint{}).TelemetryCounter())
+}
+
+func TestAlterTypeAddValueValidate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		cmd         tree.AlterTypeAddValue
+		expectedErr string
+	}{
+		{tree.AlterTypeAddValue{NewVal: "x"}, ""},
+		{
+			tree.AlterTypeAddValue{
+				NewVal:    "x",
+				Placement: &tree.AlterTypeAddValuePlacement{Before: true, ExistingVal: "y"},
+			},
+			"",
+		},
+		{
+			tree.AlterTypeAddValue{
+				NewVal:    "x",
+				Placement: &tree.AlterTypeAddValuePlacement{ExistingVal: "x"},
+			},
+			`enum label "x" cannot be placed relative to itself`,
+		},
+		{
+			tree.AlterTypeAddValue{
+				NewVal:    "x",
+				Placement: &tree.AlterTypeAddValuePlacement{Before: true, ExistingVal: "x"},
+			},
+			`enum label "x" cannot be placed relative to itself`,
+		},
+		{tree.AlterTypeAddValue{NewVal: ""}, `enum label cannot be empty`},
+		{tree.AlterTypeAddValue{NewVal: "", IfNotExists: true}, `enum label cannot be empty`},
+	}
+	for _, tc := range testCases {
+		t.Run(tree.AsString(&tc.cmd), func(t *testing.T) {
+			err := tc.cmd.Validate()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestAlterTypeAddValueConcurrencyTokenNotFormatted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, sql := range []string{
+		`ALTER TYPE t ADD VALUE 'x'`,
+		`ALTER TYPE t ADD VALUE IF NOT EXISTS 'x' BEFORE 'y'`,
+	} {
+		t.Run(sql, func(t *testing.T) {
+			stmt, err := parser.ParseOne(sql)
+			require.NoError(t, err)
+			alter := stmt.AST.(*tree.AlterType)
+			cmd := alter.Cmd.(*tree.AlterTypeAddValue)
+			require.Empty(t, cmd.ConcurrencyToken)
+			require.Equal(t, sql, tree.AsString(alter))
+
+			cmd.ConcurrencyToken = "token */ DROP"
+			require.Equal(t, sql, tree.AsString(alter))
+
+			// The token is only shown with FmtShowConcurrencyTokens, in a
+			// comment, so that the statement still parses the same.
+			shown := tree.AsStringWithFlags(alter, tree.FmtShowConcurrencyTokens)
+			require.Equal(t, sql+" /* concurrency token: token * / DROP */", shown)
+			reparsed, err := parser.ParseOne(shown)
+			require.NoError(t, err)
+			require.Equal(t, sql, tree.AsString(reparsed.AST))
+		})
+	}
+}
@@ -136,6 +136,12 @@ const (
 	// rather than string literals. For example, the bytes \x40 will be formatted
 	// as b'\x40' rather than '\x40'.
 	fmtFormatByteLiterals
+
+	// FmtShowConcurrencyTokens instructs the pretty-printer to include the
+	// ConcurrencyToken of ALTER TYPE ADD VALUE commands, as a comment. It is
+	// meant for internal uses, e.g. logging the commands the executor runs;
+	// the token is otherwise never part of the formatted statement.
+	FmtShowConcurrencyTokens
 )
 
 // Composite/derived flag definitions follow.
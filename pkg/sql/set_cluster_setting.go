@@ -31,7 +31,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/errors"
@@ -162,6 +161,11 @@ func (n *setClusterSettingNode) startExec(params runParams) error {
 		return errors.Errorf("SET CLUSTER SETTING cannot be used inside a transaction")
 	}
 
+	reason := settings.ReasonFromContext(params.ctx)
+	if n.value != nil && n.setting.RequiresReason() && reason == "" {
+		return errors.Errorf("setting '%s' requires a reason", n.name)
+	}
+
 	if !params.p.execCfg.Codec.ForSystemTenant() {
 		// Sanity check that this tenant is able to set in-memory settings.
 		if !params.p.execCfg.TenantTestingKnobs.CanSetClusterSettings() {
@@ -183,7 +187,9 @@ func (n *setClusterSettingNode) startExec(params runParams) error {
 				return err
 			}
 		}
-		return params.p.execCfg.TenantTestingKnobs.ClusterSettingsUpdater.Set(n.name, encodedValue, n.setting.Typ())
+		return params.p.execCfg.TenantTestingKnobs.ClusterSettingsUpdater.SetWithReason(
+			n.name, encodedValue, n.setting.Typ(), reason,
+		)
 	}
 
 	execCfg := params.extendedEvalCtx.ExecCfg
@@ -393,7 +399,7 @@ func toSettingString(
 		return "", errors.Errorf("cannot use %s %T value for enum setting, must be int or string", d.ResolvedType(), d)
 	case *settings.ByteSizeSetting:
 		if s, ok := d.(*tree.DString); ok {
-			bytes, err := humanizeutil.ParseBytes(string(*s))
+			bytes, err := settings.ParseByteSize(string(*s))
 			if err != nil {
 				return "", err
 			}
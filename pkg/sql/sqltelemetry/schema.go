@@ -120,6 +120,19 @@ func SchemaChangeAlterCounterWithExtra(typ string, extra string) telemetry.Count
 	return telemetry.GetCounter(fmt.Sprintf("sql.schema.alter_%s%s", typ, extra))
 }
 
+// AlterTypeCommandCountCounter is to be incremented for every ALTER TYPE
+// statement, with the number of commands in the statement bucketed as 1, 2 to
+// 5, and 6 or more.
+func AlterTypeCommandCountCounter(numCmds int) telemetry.Counter {
+	bucket := "1"
+	if numCmds >= 6 {
+		bucket = "6_or_more"
+	} else if numCmds >= 2 {
+		bucket = "2_to_5"
+	}
+	return SchemaChangeAlterCounterWithExtra("type", "commands."+bucket)
+}
+
 // SchemaSetAuditModeCounter is to be incremented every time an audit mode is set.
 func SchemaSetAuditModeCounter(mode string) telemetry.Counter {
 	return telemetry.GetCounter("sql.schema.set_audit_mode." + mode)